@@ -102,6 +102,34 @@ func FullCap() Cap {
 	return CapFromCenterHeight(centerPoint, fullHeight)
 }
 
+// MinimalBoundingCap returns a cap that contains all of the given points,
+// which must be unit length. The result is approximate: the cap is centered
+// on the normalized sum of the points, and its radius is the distance to the
+// farthest point from that center. When the points fit within a hemisphere,
+// this radius is at most twice that of the smallest enclosing cap, and it is
+// usually much closer for points clustered in a small region. If there are no
+// points, an empty cap is returned.
+func MinimalBoundingCap(points []Point) Cap {
+	if len(points) == 0 {
+		return EmptyCap()
+	}
+
+	var sum Point
+	for _, p := range points {
+		sum = Point{sum.Add(p.Vector)}
+	}
+	center := points[0]
+	if sum.Norm2() > 0 {
+		center = Point{sum.Normalize()}
+	}
+
+	c := CapFromPoint(center)
+	for _, p := range points {
+		c = c.AddPoint(p)
+	}
+	return c
+}
+
 // IsValid reports whether the Cap is considered valid.
 // Heights are normalized so that they do not exceed 2.
 func (c Cap) IsValid() bool {
@@ -349,6 +377,30 @@ func (c Cap) IntersectsCell(cell Cell) bool {
 	return c.intersects(cell, vertices)
 }
 
+// ContainsCellUnion reports whether the cap contains every cell of the given
+// CellUnion. This tests the cells directly, which is much cheaper than
+// covering the cap and comparing the two unions. An empty CellUnion is
+// contained by every cap.
+func (c Cap) ContainsCellUnion(cu CellUnion) bool {
+	for _, id := range cu {
+		if !c.ContainsCell(CellFromCellID(id)) {
+			return false
+		}
+	}
+	return true
+}
+
+// IntersectsCellUnion reports whether the cap intersects any cell of the given
+// CellUnion.
+func (c Cap) IntersectsCellUnion(cu CellUnion) bool {
+	for _, id := range cu {
+		if c.IntersectsCell(CellFromCellID(id)) {
+			return true
+		}
+	}
+	return false
+}
+
 // intersects reports whether the cap intersects any point of the cell excluding
 // its vertices (which are assumed to already have been checked).
 func (c Cap) intersects(cell Cell, vertices [4]Point) bool {
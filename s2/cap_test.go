@@ -572,3 +572,79 @@ func TestCapIntersectsCell(t *testing.T) {
 		}
 	}
 }
+
+func TestCapCellUnionOps(t *testing.T) {
+	center := PointFromLatLng(LatLngFromDegrees(40, -74))
+	id := cellIDFromPoint(center).Parent(10)
+	cellCap := CellFromCellID(id).CapBound()
+
+	// A cap well inside the cell, and a neighboring cell far enough away
+	// that the cap cannot reach it.
+	small := CapFromCenterAngle(id.Point(), s1.Angle(0.1*MinWidthMetric.Value(10)))
+	neighbor := id.Parent(8).Next()
+
+	tests := []struct {
+		cap        Cap
+		cu         CellUnion
+		contains   bool
+		intersects bool
+	}{
+		{cellCap, CellUnion{}, true, false},
+		{full, CellUnion{id, neighbor}, true, true},
+		{empty, CellUnion{id}, false, false},
+		// Fully contains every cell.
+		{cellCap, CellUnion{id}, true, true},
+		{cellCap, CellUnion{id.Children()[0], id.Children()[3]}, true, true},
+		// Partially overlaps the union.
+		{small, CellUnion{id}, false, true},
+		{cellCap, CellUnion{id, neighbor}, false, true},
+		// Disjoint from the union.
+		{small, CellUnion{neighbor}, false, false},
+	}
+
+	for _, test := range tests {
+		if got := test.cap.ContainsCellUnion(test.cu); got != test.contains {
+			t.Errorf("%v.ContainsCellUnion(%v) = %t, want %t", test.cap, test.cu, got, test.contains)
+		}
+		if got := test.cap.IntersectsCellUnion(test.cu); got != test.intersects {
+			t.Errorf("%v.IntersectsCellUnion(%v) = %t, want %t", test.cap, test.cu, got, test.intersects)
+		}
+	}
+}
+
+func TestMinimalBoundingCap(t *testing.T) {
+	if got := MinimalBoundingCap(nil); !got.IsEmpty() {
+		t.Errorf("MinimalBoundingCap(nil) = %v, want empty cap", got)
+	}
+
+	p := parsePoint("10:20")
+	if got := MinimalBoundingCap([]Point{p}); !got.ApproxEqual(CapFromPoint(p)) {
+		t.Errorf("MinimalBoundingCap(%v) = %v, want %v", p, got, CapFromPoint(p))
+	}
+
+	// The points sum to zero, so the cap is centered on the first point.
+	antipodal := []Point{p, Point{p.Mul(-1)}}
+	if got := MinimalBoundingCap(antipodal); got.Center() != p || !got.ContainsPoint(antipodal[1]) {
+		t.Errorf("MinimalBoundingCap(%v) = %v, want a cap centered on %v containing both points", antipodal, got, p)
+	}
+
+	for iter := 0; iter < 100; iter++ {
+		// Points clustered within a small cap, whose radius the optimal cap
+		// can't exceed.
+		cluster := CapFromCenterAngle(randomPoint(), s1.Angle(1e-3*math.Pow(1e3, randomFloat64())))
+		points := make([]Point, 1+randomUniformInt(50))
+		for i := range points {
+			points[i] = samplePointFromCap(cluster)
+		}
+
+		got := MinimalBoundingCap(points)
+		for _, p := range points {
+			if !got.ContainsPoint(p) {
+				t.Errorf("MinimalBoundingCap(%v) = %v, does not contain %v", points, got, p)
+			}
+		}
+		if got.Radius() > 2*cluster.Radius() {
+			t.Errorf("MinimalBoundingCap(%v).Radius() = %v, want <= %v", points, got.Radius(), 2*cluster.Radius())
+		}
+	}
+}
@@ -25,6 +25,108 @@ import "sort"
 // nor the four sibling CellIDs that are children of a single higher level CellID.
 type CellUnion []CellID
 
+// CellUnionFromUnion creates a CellUnion from the union of the given CellUnions.
+func CellUnionFromUnion(cellUnions ...CellUnion) CellUnion {
+	var cu CellUnion
+	for _, cellUnion := range cellUnions {
+		cu = append(cu, cellUnion...)
+	}
+	cu.Normalize()
+	return cu
+}
+
+// CellUnionFromIntersection creates a CellUnion from the intersection of the given CellUnions.
+//
+// This method assumes that both CellUnions have been normalized.
+func CellUnionFromIntersection(x, y CellUnion) CellUnion {
+	var cu CellUnion
+
+	// This is a fairly efficient calculation that uses binary search to skip
+	// over sections of both input vectors. It takes constant time if all the
+	// cells of x come before or after all the cells of y in CellID order.
+	for i, j := 0, 0; i < len(x) && j < len(y); {
+		iMin := x[i].RangeMin()
+		jMin := y[j].RangeMin()
+		if iMin > jMin {
+			// Either y[j] contains x[i] or the two cells are disjoint.
+			if x[i] <= y[j].RangeMax() {
+				cu = append(cu, x[i])
+				i++
+			} else {
+				// Advance j to the first cell possibly contained by x[i].
+				j = y.lowerBound(j+1, iMin)
+				// The previous cell y[j-1] may now contain x[i].
+				if x[i] <= y[j-1].RangeMax() {
+					j--
+				}
+			}
+		} else if jMin > iMin {
+			// Identical to the code above with i and j reversed.
+			if y[j] <= x[i].RangeMax() {
+				cu = append(cu, y[j])
+				j++
+			} else {
+				i = x.lowerBound(i+1, jMin)
+				if y[j] <= x[i-1].RangeMax() {
+					i--
+				}
+			}
+		} else {
+			// x[i] and y[j] have the same RangeMin(), so one contains the other.
+			if x[i] < y[j] {
+				cu = append(cu, x[i])
+				i++
+			} else {
+				cu = append(cu, y[j])
+				j++
+			}
+		}
+	}
+
+	// The output is generated in sorted order, but may contain four sibling
+	// cells that can be collapsed into their parent.
+	cu.Normalize()
+	return cu
+}
+
+// CellUnionFromDifference creates a CellUnion that contains all the cells of x
+// that are not covered by y.
+//
+// This method assumes that both CellUnions have been normalized.
+func CellUnionFromDifference(x, y CellUnion) CellUnion {
+	// TODO(roberts): This is approximately O(N*log(N)), but could probably
+	// use similar techniques as CellUnionFromIntersection to be more efficient.
+	var cu CellUnion
+	for _, id := range x {
+		cu.differenceInternal(id, &y)
+	}
+	// The output is generated in sorted order, and there should not be any
+	// cells that can be merged (provided that both inputs were normalized).
+	return cu
+}
+
+// differenceInternal appends the parts of the given cell that are not covered
+// by other to this CellUnion.
+func (cu *CellUnion) differenceInternal(id CellID, other *CellUnion) {
+	if !other.IntersectsCellID(id) {
+		*cu = append(*cu, id)
+		return
+	}
+	if other.ContainsCellID(id) {
+		return
+	}
+	for _, child := range id.Children() {
+		cu.differenceInternal(child, other)
+	}
+}
+
+// lowerBound returns the index in this CellUnion of the first element at or
+// after begin whose value is not less than id. If there is no such element,
+// the length of the CellUnion is returned.
+func (cu *CellUnion) lowerBound(begin int, id CellID) int {
+	return begin + sort.Search(len(*cu)-begin, func(i int) bool { return (*cu)[begin+i] >= id })
+}
+
 // Normalize normalizes the CellUnion.
 func (cu *CellUnion) Normalize() {
 	sort.Sort(byID(*cu))
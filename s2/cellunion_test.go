@@ -590,3 +590,184 @@ func TestCellUnionRectBound(t *testing.T) {
 		}
 	}
 }
+
+// cellUnionsEqual reports whether the two CellUnions have the same cells,
+// treating nil and empty unions as equal.
+func cellUnionsEqual(a, b CellUnion) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) == len(b)
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func TestCellUnionSetOperations(t *testing.T) {
+	parent := CellIDFromFace(1).ChildBeginAtLevel(5)
+	children := parent.Children()
+	grandchild := children[1].ChildBegin()
+	far := CellIDFromFace(4).ChildBeginAtLevel(7)
+
+	tests := []struct {
+		name         string
+		x, y         CellUnion
+		union        CellUnion
+		intersection CellUnion
+		xMinusY      CellUnion
+		yMinusX      CellUnion
+	}{
+		{
+			name:         "both empty",
+			x:            CellUnion{},
+			y:            CellUnion{},
+			union:        CellUnion{},
+			intersection: CellUnion{},
+			xMinusY:      CellUnion{},
+			yMinusX:      CellUnion{},
+		},
+		{
+			name:         "one empty",
+			x:            CellUnion{parent},
+			y:            CellUnion{},
+			union:        CellUnion{parent},
+			intersection: CellUnion{},
+			xMinusY:      CellUnion{parent},
+			yMinusX:      CellUnion{},
+		},
+		{
+			name:         "identical",
+			x:            CellUnion{parent, far},
+			y:            CellUnion{parent, far},
+			union:        CellUnion{parent, far},
+			intersection: CellUnion{parent, far},
+			xMinusY:      CellUnion{},
+			yMinusX:      CellUnion{},
+		},
+		{
+			name:         "disjoint",
+			x:            CellUnion{parent},
+			y:            CellUnion{far},
+			union:        CellUnion{parent, far},
+			intersection: CellUnion{},
+			xMinusY:      CellUnion{parent},
+			yMinusX:      CellUnion{far},
+		},
+		{
+			name:         "child in x covered by parent in y",
+			x:            CellUnion{children[1], far},
+			y:            CellUnion{parent},
+			union:        CellUnion{parent, far},
+			intersection: CellUnion{children[1]},
+			xMinusY:      CellUnion{far},
+			yMinusX:      CellUnion{children[0], children[2], children[3]},
+		},
+		{
+			name:         "grandchild in y covered by parent in x",
+			x:            CellUnion{parent},
+			y:            CellUnion{grandchild},
+			union:        CellUnion{parent},
+			intersection: CellUnion{grandchild},
+			xMinusY: CellUnion{
+				children[0],
+				grandchild.Next(),
+				grandchild.Next().Next(),
+				grandchild.Next().Next().Next(),
+				children[2],
+				children[3],
+			},
+			yMinusX: CellUnion{},
+		},
+		{
+			name:         "overlapping multi-level unions",
+			x:            CellUnion{children[0], children[1]},
+			y:            CellUnion{grandchild, children[2], children[3]},
+			union:        CellUnion{parent},
+			intersection: CellUnion{grandchild},
+			xMinusY: CellUnion{
+				children[0],
+				grandchild.Next(),
+				grandchild.Next().Next(),
+				grandchild.Next().Next().Next(),
+			},
+			yMinusX: CellUnion{children[2], children[3]},
+		},
+	}
+
+	for _, test := range tests {
+		if got := CellUnionFromUnion(test.x, test.y); !cellUnionsEqual(got, test.union) {
+			t.Errorf("%s: CellUnionFromUnion(%v, %v) = %v, want %v", test.name, test.x, test.y, got, test.union)
+		}
+		if got := CellUnionFromUnion(test.y, test.x); !cellUnionsEqual(got, test.union) {
+			t.Errorf("%s: CellUnionFromUnion(%v, %v) = %v, want %v", test.name, test.y, test.x, got, test.union)
+		}
+		if got := CellUnionFromIntersection(test.x, test.y); !cellUnionsEqual(got, test.intersection) {
+			t.Errorf("%s: CellUnionFromIntersection(%v, %v) = %v, want %v", test.name, test.x, test.y, got, test.intersection)
+		}
+		if got := CellUnionFromIntersection(test.y, test.x); !cellUnionsEqual(got, test.intersection) {
+			t.Errorf("%s: CellUnionFromIntersection(%v, %v) = %v, want %v", test.name, test.y, test.x, got, test.intersection)
+		}
+		if got := CellUnionFromDifference(test.x, test.y); !cellUnionsEqual(got, test.xMinusY) {
+			t.Errorf("%s: CellUnionFromDifference(%v, %v) = %v, want %v", test.name, test.x, test.y, got, test.xMinusY)
+		}
+		if got := CellUnionFromDifference(test.y, test.x); !cellUnionsEqual(got, test.yMinusX) {
+			t.Errorf("%s: CellUnionFromDifference(%v, %v) = %v, want %v", test.name, test.y, test.x, got, test.yMinusX)
+		}
+	}
+}
+
+// randomCellUnionUnder returns a normalized CellUnion built from random
+// descendants of the given cell down to the given level.
+func randomCellUnionUnder(id CellID, deepestLevel int) CellUnion {
+	var cu CellUnion
+	for i := 0; i < 10; i++ {
+		level := id.Level() + 1 + randomUniformInt(deepestLevel-id.Level())
+		begin := id.ChildBeginAtLevel(level)
+		numCells := int64(1) << uint(2*(level-id.Level()))
+		cu = append(cu, begin.Advance(int64(randomUint64()%uint64(numCells))))
+	}
+	cu.Normalize()
+	return cu
+}
+
+func TestCellUnionSetOperationsPseudoRandom(t *testing.T) {
+	for iter := 0; iter < 200; iter++ {
+		parent := randomCellIDForLevel(randomUniformInt(10))
+		x := randomCellUnionUnder(parent, parent.Level()+8)
+		y := randomCellUnionUnder(parent, parent.Level()+8)
+
+		union := CellUnionFromUnion(x, y)
+		intersection := CellUnionFromIntersection(x, y)
+		difference := CellUnionFromDifference(x, y)
+
+		for _, cu := range []CellUnion{union, intersection, difference} {
+			normalized := append(CellUnion(nil), cu...)
+			normalized.Normalize()
+			if !cellUnionsEqual(cu, normalized) {
+				t.Errorf("%v is not normalized, want %v", cu, normalized)
+			}
+		}
+
+		// Probe the leaf cells on either side of every cell boundary in
+		// either input, as well as some random leaf cells under the parent.
+		var probes []CellID
+		for _, id := range append(append(CellUnion(nil), x...), y...) {
+			probes = append(probes, id.RangeMin(), id.RangeMax(), id.RangeMin().Prev(), id.RangeMax().Next())
+		}
+		numLeaves := int64(1) << uint(2*(maxLevel-parent.Level()))
+		for i := 0; i < 20; i++ {
+			probes = append(probes, parent.RangeMin().Advance(int64(randomUint64()%uint64(numLeaves))))
+		}
+
+		for _, leaf := range probes {
+			inX := x.ContainsCellID(leaf)
+			inY := y.ContainsCellID(leaf)
+			if got, want := union.ContainsCellID(leaf), inX || inY; got != want {
+				t.Errorf("CellUnionFromUnion(%v, %v).ContainsCellID(%v) = %t, want %t", x, y, leaf, got, want)
+			}
+			if got, want := intersection.ContainsCellID(leaf), inX && inY; got != want {
+				t.Errorf("CellUnionFromIntersection(%v, %v).ContainsCellID(%v) = %t, want %t", x, y, leaf, got, want)
+			}
+			if got, want := difference.ContainsCellID(leaf), inX && !inY; got != want {
+				t.Errorf("CellUnionFromDifference(%v, %v).ContainsCellID(%v) = %t, want %t", x, y, leaf, got, want)
+			}
+		}
+	}
+}
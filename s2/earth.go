@@ -0,0 +1,37 @@
+/*
+Copyright 2016 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package s2
+
+import (
+	"github.com/golang/geo/s1"
+)
+
+// earthRadiusKm is the Earth's mean radius in kilometers (according to NASA).
+const earthRadiusKm = 6371.01
+
+// AngleToMeters returns the distance on the Earth's surface, in meters, that
+// corresponds to the given angle, modeling the Earth as a sphere with its
+// mean radius.
+func AngleToMeters(a s1.Angle) float64 {
+	return a.Radians() * earthRadiusKm * 1000
+}
+
+// MetersToAngle returns the angle that corresponds to the given distance on
+// the Earth's surface in meters. It is the inverse of AngleToMeters.
+func MetersToAngle(meters float64) s1.Angle {
+	return s1.Angle(meters / (earthRadiusKm * 1000))
+}
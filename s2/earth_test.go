@@ -0,0 +1,54 @@
+/*
+Copyright 2016 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package s2
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/geo/s1"
+)
+
+func TestAngleToMeters(t *testing.T) {
+	tests := []struct {
+		angle  s1.Angle
+		meters float64
+	}{
+		{0, 0},
+		{1 * s1.Radian, 6371010},
+		{-1 * s1.Radian, -6371010},
+		{math.Pi * s1.Radian, math.Pi * 6371010},
+		{1 * s1.Degree, 111195.10117748394},
+		{s1.Angle(1 / 6371010.0), 1},
+	}
+	for _, test := range tests {
+		if got := AngleToMeters(test.angle); !float64Near(got, test.meters, 1e-9) {
+			t.Errorf("AngleToMeters(%v) = %v, want %v", test.angle, got, test.meters)
+		}
+		if got := MetersToAngle(test.meters); !float64Near(got.Radians(), test.angle.Radians(), 1e-15) {
+			t.Errorf("MetersToAngle(%v) = %v, want %v", test.meters, got, test.angle)
+		}
+		if got := MetersToAngle(AngleToMeters(test.angle)); !float64Near(got.Radians(), test.angle.Radians(), 1e-15) {
+			t.Errorf("MetersToAngle(AngleToMeters(%v)) = %v, want %v", test.angle, got, test.angle)
+		}
+	}
+
+	// Kilometers and meters agree with each other.
+	if got, want := MetersToAngle(1000), kmToAngle(1); !float64Eq(got.Radians(), want.Radians()) {
+		t.Errorf("MetersToAngle(1000) = %v, want kmToAngle(1) = %v", got, want)
+	}
+}
@@ -18,9 +18,12 @@ package s2
 
 import (
 	"math"
+	"math/big"
+	"sort"
 
 	"github.com/golang/geo/r1"
 	"github.com/golang/geo/r2"
+	"github.com/golang/geo/r3"
 	"github.com/golang/geo/s1"
 )
 
@@ -70,6 +73,10 @@ var (
 	// needed.
 	intersectionError = s1.Angle(4 * dblEpsilon)
 
+	// dblError is the maximum relative error of a single rounded floating
+	// point operation, i.e. half of dblEpsilon.
+	dblError = dblEpsilon / 2
+
 	// intersectionMergeRadius is used to ensure that intersection points that
 	// are supposed to be coincident are merged back together into a single
 	// vertex. This is required in order for various polygon operations (union,
@@ -186,6 +193,487 @@ func InterpolateAtDistance(ax s1.Angle, a, b Point) Point {
 	return Point{(a.Mul(math.Cos(aRad)).Add(tangent.Mul(math.Sin(aRad) / tangent.Norm()))).Normalize()}
 }
 
+// Densify returns the vertices of the edges of the given shape, with extra
+// points interpolated along each edge so that no straight chord between two
+// consecutive points deviates from the geodesic edge by more than
+// maxDeviation. The deviation is a straight-line distance on the unit sphere,
+// not an angle: a chord spanning an angle θ deviates by the distance from its
+// midpoint to the sphere, which is 1 - cos(θ/2). To bound the deviation on
+// the Earth in meters, divide it by the Earth's radius in meters. This is
+// useful when projecting geodesic edges onto a map and drawing them as
+// straight lines.
+//
+// The edges are emitted in order. The start of an edge is only emitted if it
+// differs from the end of the previous edge, so the edges of a Loop produce a
+// closed chain whose last point equals its first. If maxDeviation is not
+// positive, no extra points are added.
+func Densify(shape Shape, maxDeviation float64) []Point {
+	// The largest angle that a chord may span while staying within
+	// maxDeviation of the sphere, computed as 1 - cos(θ/2) = 2*sin²(θ/4)
+	// to avoid cancellation for small deviations.
+	maxSpan := s1.Angle(math.Pi)
+	if maxDeviation <= 0 {
+		maxSpan = s1.Angle(math.Inf(1))
+	} else if maxDeviation < 2 {
+		maxSpan = s1.Angle(4 * math.Asin(math.Sqrt(maxDeviation/2)))
+	}
+
+	var points []Point
+	for i := 0; i < shape.NumEdges(); i++ {
+		a, b := shape.Edge(i)
+		if len(points) == 0 || points[len(points)-1] != a {
+			points = append(points, a)
+		}
+		n := int(math.Ceil(float64(a.Distance(b) / maxSpan)))
+		for k := 1; k < n; k++ {
+			points = append(points, Interpolate(float64(k)/float64(n), a, b))
+		}
+		points = append(points, b)
+	}
+	return points
+}
+
+// DistanceFromSegment returns the distance of point X from line segment AB.
+// The points are expected to be normalized. The result is very accurate for
+// small distances but may have some numerical error if the distance is large
+// (approximately Pi/2 or greater). The case A == B is handled correctly.
+func DistanceFromSegment(x, a, b Point) s1.Angle {
+	aCrossB := a.PointCross(b)
+
+	// There are three cases. If X is located in the spherical wedge defined
+	// by A, B, and the axis A x B, then the closest point is on the segment
+	// AB. Otherwise the closest point is either A or B; the dividing line
+	// between these two cases is the great circle passing through (A x B) and
+	// the midpoint of AB.
+	if Sign(aCrossB, a, x) && Sign(x, b, aCrossB) {
+		// The closest point to X lies on the segment AB. We compute the
+		// distance to the corresponding great circle. The result is accurate
+		// for small distances but not necessarily for large distances
+		// (approaching Pi/2).
+		sinDist := math.Abs(x.Dot(aCrossB.Vector)) / aCrossB.Norm()
+		return s1.Angle(math.Asin(math.Min(1, sinDist)))
+	}
+
+	// Otherwise, the closest point is either A or B. The cheapest method is
+	// just to compute the minimum of the two linear (as opposed to spherical)
+	// distances and convert the result to an angle. Again, this method is
+	// accurate for small but not large distances (approaching Pi).
+	linearDist2 := math.Min(x.Sub(a.Vector).Norm2(), x.Sub(b.Vector).Norm2())
+	return s1.Angle(2 * math.Asin(math.Min(1, 0.5*math.Sqrt(linearDist2))))
+}
+
+// MaxDeviation returns the maximum distance from any vertex of the original
+// polyline to the nearest edge of the simplified polyline. This can be used
+// to check that a simplified polyline stays within the tolerance it was
+// simplified with. A simplified polyline with a single vertex is treated as
+// that point. If either polyline has no vertices, zero is returned.
+func MaxDeviation(original, simplified []Point) s1.Angle {
+	if len(original) == 0 || len(simplified) == 0 {
+		return 0
+	}
+
+	var maxDist s1.Angle
+	for _, x := range original {
+		dist := x.Distance(simplified[0])
+		for i := 1; i < len(simplified); i++ {
+			if d := DistanceFromSegment(x, simplified[i-1], simplified[i]); d < dist {
+				dist = d
+			}
+		}
+		if dist > maxDist {
+			maxDist = dist
+		}
+	}
+	return maxDist
+}
+
+// Intersection returns the intersection point of two edges AB and CD that
+// cross (CrossingSign(a,b,c,d) == Cross or VertexCrossing(a,b,c,d) is true).
+// The result is within intersectionError of the true intersection point.
+//
+// Useful properties of Intersection:
+//
+//  (1) Intersection(b,a,c,d) == Intersection(a,b,d,c) == Intersection(a,b,c,d)
+//  (2) Intersection(c,d,a,b) == Intersection(a,b,c,d)
+func Intersection(a0, a1, b0, b1 Point) Point {
+	x, _ := IntersectionWithError(a0, a1, b0, b1)
+	return x
+}
+
+// IntersectionWithError is like Intersection, but also returns an upper bound
+// on the angle between the returned point and the true intersection point.
+// The bound is never larger than intersectionError, and is usually much
+// smaller unless the edges are nearly parallel. Callers that need to merge
+// intersection points that should be coincident can use twice this bound as
+// their tolerance.
+func IntersectionWithError(a0, a1, b0, b1 Point) (Point, s1.Angle) {
+	// It is difficult to compute the intersection point of two edges
+	// accurately when the angle between the edges is very small. Our strategy
+	// is to first try a stable floating point method that comes with an error
+	// bound, and to fall back to exact arithmetic if that bound is too large.
+	x, bound, ok := intersectionStable(a0, a1, b0, b1)
+	if !ok {
+		x, bound = intersectionExact(a0, a1, b0, b1)
+	}
+
+	// Make sure the intersection point is on the correct side of the sphere.
+	// Since all vertices are unit length, and edges are less than 180
+	// degrees, (a0 + a1) and (b0 + b1) both have positive dot product with
+	// the intersection point. We use the sum of all vertices to make sure
+	// that the result is unchanged when the edges are swapped or reversed.
+	if x.Dot((a0.Add(a1.Vector)).Add(b0.Add(b1.Vector))) < 0 {
+		x = Point{x.Mul(-1)}
+	}
+	return x, s1.Angle(bound)
+}
+
+// Edge is a geodesic edge between the vertices V0 and V1.
+type Edge struct {
+	V0, V1 Point
+}
+
+// SplitEdgesAtCrossings splits the given edges at every point where two of
+// them cross at a point interior to both edges, and returns the resulting
+// pieces. Each edge is replaced by its pieces in order from V0 to V1, so the
+// result contains the same edges in the same order when no edges cross. For
+// example, two edges that cross each other are split into four edges that
+// share the intersection point as a vertex.
+//
+// The result is consistently noded: all of the crossing points are computed
+// first, and points within intersectionMergeRadius of each other or of an
+// edge endpoint are merged into a single shared vertex. This means that when
+// several edges cross at (nearly) the same point, all of their pieces meet at
+// exactly the same vertex and no tiny edges are created. Edges that only
+// touch at a vertex are not split. Every pair of edges is tested, so the cost
+// is quadratic in the number of edges. Merging each crossing point takes
+// constant time on average, since nearby vertices are found by cell.
+func SplitEdgesAtCrossings(edges []Edge) []Edge {
+	// The vertices that crossing points are merged into. The edge endpoints
+	// come first so that a crossing very close to an endpoint is snapped to
+	// it rather than creating a tiny edge.
+	vertices := newVertexMerger(intersectionMergeRadius)
+	for _, e := range edges {
+		vertices.add(e.V0)
+		vertices.add(e.V1)
+	}
+
+	// splits holds, for each edge, the shared vertices it must be split at.
+	splits := make([][]Point, len(edges))
+	for i, e := range edges {
+		crosser := NewEdgeCrosser(e.V0, e.V1)
+		for j := i + 1; j < len(edges); j++ {
+			other := edges[j]
+			if crosser.CrossingSign(other.V0, other.V1) != Cross {
+				continue
+			}
+			x := vertices.merge(Intersection(e.V0, e.V1, other.V0, other.V1))
+			splits[i] = append(splits[i], x)
+			splits[j] = append(splits[j], x)
+		}
+	}
+
+	var result []Edge
+	for i, e := range edges {
+		points := byDistanceFrom{origin: e.V0, points: splits[i]}
+		sort.Sort(points)
+
+		start := e.V0
+		for _, x := range points.points {
+			// Skip vertices that several crossings were merged into, as well
+			// as crossings that were snapped to one of the endpoints.
+			if x == start || x == e.V1 {
+				continue
+			}
+			result = append(result, Edge{start, x})
+			start = x
+		}
+		result = append(result, Edge{start, e.V1})
+	}
+	return result
+}
+
+// vertexMerger is a set of vertices that new points can be merged into if
+// they are within a given radius of an existing vertex. The vertices are
+// bucketed by the cell containing them at a level whose cells are at least
+// twice as wide as the radius, so any vertex within the radius of a point is
+// in one of the (at most four) cells around the cell vertex closest to it.
+type vertexMerger struct {
+	radius   s1.Angle
+	level    int
+	vertices []Point
+	cells    map[CellID][]int
+}
+
+// newVertexMerger returns an empty vertexMerger with the given merge radius.
+func newVertexMerger(radius s1.Angle) *vertexMerger {
+	level := MinWidthMetric.MaxLevel(2 * float64(radius))
+	if level >= maxLevel {
+		// VertexNeighbors needs cells smaller than those at the given level.
+		level = maxLevel - 1
+	}
+	return &vertexMerger{
+		radius: radius,
+		level:  level,
+		cells:  make(map[CellID][]int),
+	}
+}
+
+// add adds the given point as a vertex without merging it.
+func (m *vertexMerger) add(p Point) {
+	id := cellIDFromPoint(p).Parent(m.level)
+	m.cells[id] = append(m.cells[id], len(m.vertices))
+	m.vertices = append(m.vertices, p)
+}
+
+// merge returns the earliest added vertex that is within the merge radius of
+// x. If there is none, x is added as a vertex and returned.
+func (m *vertexMerger) merge(x Point) Point {
+	best := -1
+	for _, id := range cellIDFromPoint(x).VertexNeighbors(m.level) {
+		for _, i := range m.cells[id] {
+			if (best < 0 || i < best) && m.vertices[i].Distance(x) <= m.radius {
+				best = i
+			}
+		}
+	}
+	if best >= 0 {
+		return m.vertices[best]
+	}
+	m.add(x)
+	return x
+}
+
+// byDistanceFrom sorts points by their distance from a fixed origin.
+type byDistanceFrom struct {
+	origin Point
+	points []Point
+}
+
+func (s byDistanceFrom) Len() int      { return len(s.points) }
+func (s byDistanceFrom) Swap(i, j int) { s.points[i], s.points[j] = s.points[j], s.points[i] }
+func (s byDistanceFrom) Less(i, j int) bool {
+	return s.origin.Distance(s.points[i]) < s.origin.Distance(s.points[j])
+}
+
+// intersectionStable computes the intersection point of the edges (a0,a1)
+// and (b0,b1) along with an error bound in radians. It reports false if the
+// error bound would exceed intersectionError.
+func intersectionStable(a0, a1, b0, b1 Point) (Point, float64, bool) {
+	// Sort the two edges so that (a0,a1) is longer, breaking ties in a
+	// deterministic way that does not depend on the ordering of the
+	// endpoints. This makes the result independent of how the edges are
+	// swapped or reversed, and it reduces error, since the first edge is
+	// used to compute the edge normal (where a longer edge means less error)
+	// and the second edge is used for interpolation (where a shorter edge
+	// means less error).
+	aLen2 := a1.Sub(a0.Vector).Norm2()
+	bLen2 := b1.Sub(b0.Vector).Norm2()
+	if aLen2 < bLen2 || (aLen2 == bLen2 && edgeLess(a0, a1, b0, b1)) {
+		return intersectionStableSorted(b0, b1, a0, a1)
+	}
+	return intersectionStableSorted(a0, a1, b0, b1)
+}
+
+// intersectionStableSorted is the helper for intersectionStable. It expects
+// (a0,a1) to be the longer of the two edges.
+func intersectionStableSorted(a0, a1, b0, b1 Point) (Point, float64, bool) {
+	// Compute the normal of the plane through (a0, a1) in a stable way.
+	aNorm := a0.Sub(a1.Vector).Cross(a0.Add(a1.Vector))
+	aNormLen := aNorm.Norm()
+	bLen := b1.Sub(b0.Vector).Norm()
+
+	// Compute the signed distances from b0 and b1 to the plane.
+	b0Dist, b0Error := projection(b0.Vector, aNorm, aNormLen, a0, a1)
+	b1Dist, b1Error := projection(b1.Vector, aNorm, aNormLen, a0, a1)
+
+	// The total distance from b0 to b1 measured perpendicularly to (a0,a1) is
+	// |b0Dist - b1Dist|. Note that b0Dist and b1Dist generally have opposite
+	// signs because b0 and b1 are on opposite sides of (a0, a1). The
+	// intersection point is found by interpolating along the edge (b0, b1)
+	// to a fractional distance of b0Dist / (b0Dist - b1Dist).
+	//
+	// The maximum error in the interpolation fraction is
+	//
+	//   (b0Dist * b1Error - b1Dist * b0Error) / (distSum * (distSum - errorSum))
+	//
+	// We save ourselves some work by scaling the result and the error bound
+	// by distSum, since the result is normalized to be unit length anyway.
+	distSum := math.Abs(b0Dist - b1Dist)
+	errorSum := b0Error + b1Error
+	if distSum <= errorSum {
+		// The error is unbounded in this case.
+		return Point{}, 0, false
+	}
+	x := b1.Mul(b0Dist).Sub(b0.Mul(b1Dist))
+	err := bLen*math.Abs(b0Dist*b1Error-b1Dist*b0Error)/(distSum-errorSum) + 2*dblError*distSum
+
+	// Finally we normalize the result, compute the corresponding error, and
+	// check whether the total error is acceptable.
+	xLen2 := x.Norm2()
+	if xLen2 < 0x1p-1022 {
+		// x has lost too much precision to be normalized reliably.
+		return Point{}, 0, false
+	}
+	xLen := math.Sqrt(xLen2)
+	if err > (float64(intersectionError)-dblError)*xLen {
+		return Point{}, 0, false
+	}
+	return Point{x.Mul(1 / xLen)}, err/xLen + dblError, true
+}
+
+// projection returns the dot product of x with the (not necessarily unit
+// length) normal aNorm of the edge (a0,a1), whose length is aNormLen, along
+// with a bound on the error in the result. The points a0 and a1 are the edge
+// endpoints, and are used to reduce the error of the computation.
+func projection(x, aNorm r3.Vector, aNormLen float64, a0, a1 Point) (proj, bound float64) {
+	// The error in the dot product is proportional to the lengths of the
+	// input vectors, so rather than using x itself (a unit-length vector) we
+	// use the vectors from x to the closer of the two edge endpoints. This
+	// typically reduces the error by a huge factor.
+	x0 := x.Sub(a0.Vector)
+	x1 := x.Sub(a1.Vector)
+	x0Dist2 := x0.Norm2()
+	x1Dist2 := x1.Norm2()
+
+	// If both distances are the same, we need to be careful to choose one
+	// endpoint deterministically so that the result does not change if the
+	// order of the endpoints is reversed.
+	var dist float64
+	if x0Dist2 < x1Dist2 || (x0Dist2 == x1Dist2 && vectorLess(x0, x1)) {
+		dist = math.Sqrt(x0Dist2)
+		proj = x0.Dot(aNorm)
+	} else {
+		dist = math.Sqrt(x1Dist2)
+		proj = x1.Dot(aNorm)
+	}
+
+	// This calculation bounds the error from all sources: the computation of
+	// the normal, the subtraction of one endpoint, and the dot product
+	// itself. For reference, the bounds that went into this calculation are:
+	//
+	//   ||N'-N|| <= ((1 + 2 * sqrt(3))||N|| + 32 * sqrt(3) * dblError) * dblError
+	//   |(A.B)'-(A.B)| <= (1.5 * (A.B) + 1.5 * ||A|| * ||B||) * dblError
+	//   ||(X-Y)'-(X-Y)|| <= ||X-Y|| * dblError
+	bound = (((3.5+2*math.Sqrt(3))*aNormLen+32*math.Sqrt(3)*dblError)*dist + 1.5*math.Abs(proj)) * dblError
+	return proj, bound
+}
+
+// intersectionExact computes the intersection point of the edges (a0,a1) and
+// (b0,b1) using exact arithmetic, and returns it along with an error bound in
+// radians. The sign of the result is arbitrary.
+func intersectionExact(a0, a1, b0, b1 Point) (Point, float64) {
+	aNorm := exactCross(exactVector(a0.Vector), exactVector(a1.Vector))
+	bNorm := exactCross(exactVector(b0.Vector), exactVector(b1.Vector))
+	x := exactCross(aNorm, bNorm)
+	if !exactIsZero(x) {
+		// The exact result only loses precision when it is rounded to
+		// float64 and normalized, which is at most one rounding error each.
+		return Point{exactToVector(x).Normalize()}, 2 * dblError
+	}
+
+	// The two edges are exactly collinear, but we still consider them to be
+	// crossing because of simulation of simplicity. Out of the four
+	// endpoints, exactly two lie in the interior of the other edge. Of those
+	// two we return the one that is lexicographically smallest, which is an
+	// exact input vertex.
+	aNormal := Point{exactToVector(aNorm)}
+	bNormal := Point{exactToVector(bNorm)}
+	var candidates []Point
+	if OrderedCCW(b0, a0, b1, bNormal) {
+		candidates = append(candidates, a0)
+	}
+	if OrderedCCW(b0, a1, b1, bNormal) {
+		candidates = append(candidates, a1)
+	}
+	if OrderedCCW(a0, b0, a1, aNormal) {
+		candidates = append(candidates, b0)
+	}
+	if OrderedCCW(a0, b1, a1, aNormal) {
+		candidates = append(candidates, b1)
+	}
+	if len(candidates) == 0 {
+		// This only happens if one of the edges is degenerate.
+		return a0, 0
+	}
+	x0 := candidates[0]
+	for _, c := range candidates[1:] {
+		if vectorLess(c.Vector, x0.Vector) {
+			x0 = c
+		}
+	}
+	return x0, 0
+}
+
+// vectorLess reports whether a is lexicographically smaller than b.
+func vectorLess(a, b r3.Vector) bool {
+	if a.X != b.X {
+		return a.X < b.X
+	}
+	if a.Y != b.Y {
+		return a.Y < b.Y
+	}
+	return a.Z < b.Z
+}
+
+// edgeLess reports whether the edge (a0,a1) is lexicographically smaller than
+// the edge (b0,b1), comparing the endpoints of each edge in sorted order so
+// that the result does not depend on the direction of the edges.
+func edgeLess(a0, a1, b0, b1 Point) bool {
+	if vectorLess(a1.Vector, a0.Vector) {
+		a0, a1 = a1, a0
+	}
+	if vectorLess(b1.Vector, b0.Vector) {
+		b0, b1 = b1, b0
+	}
+	if a0 != b0 {
+		return vectorLess(a0.Vector, b0.Vector)
+	}
+	return vectorLess(a1.Vector, b1.Vector)
+}
+
+// exactVector returns the components of v as exact big.Floats.
+func exactVector(v r3.Vector) [3]*big.Float {
+	return [3]*big.Float{big.NewFloat(v.X), big.NewFloat(v.Y), big.NewFloat(v.Z)}
+}
+
+// exactCross returns the cross product of a and b without any rounding.
+func exactCross(a, b [3]*big.Float) [3]*big.Float {
+	mul := func(x, y *big.Float) *big.Float { return new(big.Float).SetPrec(big.MaxPrec).Mul(x, y) }
+	sub := func(x, y *big.Float) *big.Float { return new(big.Float).SetPrec(big.MaxPrec).Sub(x, y) }
+	return [3]*big.Float{
+		sub(mul(a[1], b[2]), mul(a[2], b[1])),
+		sub(mul(a[2], b[0]), mul(a[0], b[2])),
+		sub(mul(a[0], b[1]), mul(a[1], b[0])),
+	}
+}
+
+// exactIsZero reports whether all components of v are zero.
+func exactIsZero(v [3]*big.Float) bool {
+	return v[0].Sign() == 0 && v[1].Sign() == 0 && v[2].Sign() == 0
+}
+
+// exactToVector returns v rounded to float64 after scaling it so that its
+// largest component has a magnitude in [0.5, 1). The scaling preserves the
+// direction of v while avoiding underflow and overflow.
+func exactToVector(v [3]*big.Float) r3.Vector {
+	maxExp := math.MinInt32
+	for _, c := range v {
+		if c.Sign() != 0 {
+			if e := c.MantExp(nil); e > maxExp {
+				maxExp = e
+			}
+		}
+	}
+	if maxExp == math.MinInt32 {
+		return r3.Vector{}
+	}
+	var f [3]float64
+	for i, c := range v {
+		f[i], _ = new(big.Float).SetMantExp(c, -maxExp).Float64()
+	}
+	return r3.Vector{f[0], f[1], f[2]}
+}
+
 // RectBounder is used to compute a bounding rectangle that contains all edges
 // defined by a vertex chain (v0, v1, v2, ...). All vertices must be unit length.
 // Note that the bounding rectangle of an edge can be larger than the bounding
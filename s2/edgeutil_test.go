@@ -335,6 +335,307 @@ func TestRepeatedInterpolation(t *testing.T) {
 	}
 }
 
+func TestDensify(t *testing.T) {
+	a := PointFromLatLng(LatLngFromDegrees(0, 0))
+	b := PointFromLatLng(LatLngFromDegrees(0, 90))
+	normal := a.PointCross(b)
+
+	tests := []struct {
+		maxDeviation float64
+		wantPoints   int
+	}{
+		// A deviation large enough that a 90 degree edge needs no extra points.
+		{0.5, 2},
+		// 1 - cos(45/2 degrees) is about 0.076, so the edge must be split in two.
+		{0.1, 3},
+		{1e-3, 19},
+		{1e-6, 557},
+		{-1, 2},
+	}
+
+	for _, test := range tests {
+		got := Densify(&testShape{a, b, 1}, test.maxDeviation)
+		if len(got) != test.wantPoints {
+			t.Errorf("len(Densify(%v-%v, %v)) = %d, want %d", a, b, test.maxDeviation, len(got), test.wantPoints)
+		}
+		if got[0] != a || got[len(got)-1] != b {
+			t.Errorf("Densify(%v-%v, %v) endpoints = %v, %v, want %v, %v", a, b, test.maxDeviation, got[0], got[len(got)-1], a, b)
+		}
+		for i, p := range got {
+			if math.Abs(p.Dot(normal.Vector)) > 1e-15 {
+				t.Errorf("Densify(%v-%v, %v)[%d] = %v is not on the edge", a, b, test.maxDeviation, i, p)
+			}
+			if i == 0 || test.maxDeviation <= 0 {
+				continue
+			}
+			if deviation := 1 - got[i-1].Add(p.Vector).Mul(0.5).Norm(); deviation > test.maxDeviation {
+				t.Errorf("Densify(%v-%v, %v) chord %d deviates by %v", a, b, test.maxDeviation, i, deviation)
+			}
+		}
+	}
+
+	// The edges of a loop form a closed chain without repeated vertices.
+	loop := LoopFromPoints(parsePoints("0:0, 0:10, 10:10"))
+	got := Densify(loop, 1e-3)
+	if got[0] != got[len(got)-1] {
+		t.Errorf("Densify(%v) = %v, want a closed chain", loop, got)
+	}
+	for i := 1; i < len(got); i++ {
+		if got[i] == got[i-1] {
+			t.Errorf("Densify(%v) has a repeated point at %d", loop, i)
+		}
+	}
+}
+
+func TestSplitEdgesAtCrossings(t *testing.T) {
+	edge := func(s string) Edge {
+		p := parsePoints(s)
+		return Edge{p[0], p[1]}
+	}
+	tests := []struct {
+		name  string
+		edges []Edge
+		want  []int // The number of pieces each edge is split into.
+	}{
+		{"no edges", nil, nil},
+		{"crossing edges", []Edge{edge("0:-1, 0:1"), edge("-1:0, 1:0")}, []int{2, 2}},
+		{"edge crossed twice", []Edge{edge("0:-2, 0:2"), edge("-1:-1, 1:-1"), edge("-1:1, 1:1")}, []int{3, 2, 2}},
+		{"disjoint edges", []Edge{edge("0:0, 0:1"), edge("1:0, 1:1")}, []int{1, 1}},
+		{"shared vertex", []Edge{edge("0:0, 0:1"), edge("0:1, 1:1")}, []int{1, 1}},
+	}
+	for _, test := range tests {
+		got := SplitEdgesAtCrossings(test.edges)
+		wantLen := 0
+		for _, n := range test.want {
+			wantLen += n
+		}
+		if len(got) != wantLen {
+			t.Errorf("%s: SplitEdgesAtCrossings(%v) = %v, want %d edges", test.name, test.edges, got, wantLen)
+			continue
+		}
+
+		// The pieces of each edge must form a chain from its first to its
+		// last vertex.
+		k := 0
+		for i, e := range test.edges {
+			pieces := got[k : k+test.want[i]]
+			k += test.want[i]
+			if pieces[0].V0 != e.V0 || pieces[len(pieces)-1].V1 != e.V1 {
+				t.Errorf("%s: pieces %v of %v do not start and end at its vertices", test.name, pieces, e)
+			}
+			for j := 1; j < len(pieces); j++ {
+				if pieces[j-1].V1 != pieces[j].V0 {
+					t.Errorf("%s: pieces %v of %v do not form a chain", test.name, pieces, e)
+				}
+			}
+		}
+	}
+
+	// Both edges are split at exactly the same point.
+	got := SplitEdgesAtCrossings([]Edge{edge("0:-1, 0:1"), edge("-1:0, 1:0")})
+	if x := got[0].V1; x != got[2].V1 || !x.ApproxEqual(parsePoint("0:0")) {
+		t.Errorf("SplitEdgesAtCrossings split the edges at %v and %v, want both at %v", got[0].V1, got[2].V1, parsePoint("0:0"))
+	}
+}
+
+func TestSplitEdgesAtCrossingsConcurrent(t *testing.T) {
+	// Three edges through a common point, whose pairwise intersections are
+	// computed separately but must be merged into a single vertex.
+	for iter := 0; iter < 2000; iter++ {
+		f := randomFrame()
+		p := f.col(2)
+		length := 1e-6 * math.Pow(1e6, randomFloat64())
+		var edges []Edge
+		for k := 0; k < 3; k++ {
+			theta := float64(k)*math.Pi/3 + randomUniformFloat64(-0.3, 0.3)
+			d := f.col(0).Mul(math.Cos(theta)).Add(f.col(1).Mul(math.Sin(theta)))
+			fraction := randomUniformFloat64(0.1, 0.9)
+			edges = append(edges, Edge{
+				Point{p.Sub(d.Mul(fraction * length)).Normalize()},
+				Point{p.Add(d.Mul((1 - fraction) * length)).Normalize()},
+			})
+		}
+
+		got := SplitEdgesAtCrossings(edges)
+		if len(got) != 6 {
+			t.Errorf("SplitEdgesAtCrossings(%v) = %v, want 6 edges", edges, got)
+			continue
+		}
+		x := got[0].V1
+		for k := 0; k < 3; k++ {
+			if got[2*k].V1 != x || got[2*k+1].V0 != x {
+				t.Errorf("SplitEdgesAtCrossings(%v) = %v, want all pieces to meet at %v", edges, got, x)
+				break
+			}
+		}
+		if d := x.Distance(p); d > 2*intersectionMergeRadius {
+			t.Errorf("SplitEdgesAtCrossings(%v) merged the crossings at %v, which is %v from %v", edges, x, d, p)
+		}
+	}
+}
+
+func TestVertexMerger(t *testing.T) {
+	const radius = s1.Angle(1e-6)
+	for i := 0; i < 1000; i++ {
+		m := newVertexMerger(radius)
+		// Place the vertex near a corner of a cell at the bucketing level,
+		// where the points within the radius span several cells.
+		corner := CellFromCellID(randomCellIDForLevel(m.level)).Vertex(randomUniformInt(4))
+		v := InterpolateAtDistance(s1.Angle(randomFloat64())*radius/2, corner, randomPoint())
+		m.add(v)
+
+		near := InterpolateAtDistance(s1.Angle(randomFloat64())*radius/2, corner, randomPoint())
+		if got := m.merge(near); got != v {
+			t.Errorf("merge(%v) = %v, want it merged into %v at distance %v", near, got, v, near.Distance(v))
+		}
+		far := InterpolateAtDistance(3*radius, v, randomPoint())
+		if got := m.merge(far); got != far {
+			t.Errorf("merge(%v) = %v, want it added as a new vertex", far, got)
+		}
+		if got := m.merge(far); got != far {
+			t.Errorf("merge(%v) again = %v, want the vertex added before", far, got)
+		}
+	}
+}
+
+func TestDistanceFromSegment(t *testing.T) {
+	tests := []struct {
+		x, a, b r3.Vector
+		want    float64
+	}{
+		{r3.Vector{1, 0, 0}, r3.Vector{1, 0, 0}, r3.Vector{0, 1, 0}, 0},
+		{r3.Vector{0, 1, 0}, r3.Vector{1, 0, 0}, r3.Vector{0, 1, 0}, 0},
+		{r3.Vector{1, 3, 0}, r3.Vector{1, 0, 0}, r3.Vector{0, 1, 0}, 0},
+		{r3.Vector{0, 0, 1}, r3.Vector{1, 0, 0}, r3.Vector{0, 1, 0}, math.Pi / 2},
+		{r3.Vector{0, 0, -1}, r3.Vector{1, 0, 0}, r3.Vector{0, 1, 0}, math.Pi / 2},
+		{r3.Vector{-1, -1, 0}, r3.Vector{1, 0, 0}, r3.Vector{0, 1, 0}, 0.75 * math.Pi},
+		{r3.Vector{0, 1, 0}, r3.Vector{1, 0, 0}, r3.Vector{1, 1, 0}, math.Pi / 4},
+		{r3.Vector{0, -1, 0}, r3.Vector{1, 0, 0}, r3.Vector{1, 1, 0}, math.Pi / 2},
+		{r3.Vector{0, -1, 0}, r3.Vector{1, 0, 0}, r3.Vector{-1, 1, 0}, math.Pi / 2},
+		{r3.Vector{-1, -1, 0}, r3.Vector{1, 0, 0}, r3.Vector{-1, 1, 0}, math.Pi / 2},
+		{r3.Vector{1, 1, 1}, r3.Vector{1, 0, 0}, r3.Vector{0, 1, 0}, math.Asin(math.Sqrt(1.0 / 3))},
+		{r3.Vector{1, 1, -1}, r3.Vector{1, 0, 0}, r3.Vector{0, 1, 0}, math.Asin(math.Sqrt(1.0 / 3))},
+		{r3.Vector{-1, 0, 0}, r3.Vector{1, 1, 0}, r3.Vector{1, 1, 0}, 0.75 * math.Pi},
+		{r3.Vector{0, 0, -1}, r3.Vector{1, 1, 0}, r3.Vector{1, 1, 0}, math.Pi / 2},
+		{r3.Vector{-1, 0, 0}, r3.Vector{1, 0, 0}, r3.Vector{1, 0, 0}, math.Pi},
+	}
+	for _, test := range tests {
+		x := Point{test.x.Normalize()}
+		a := Point{test.a.Normalize()}
+		b := Point{test.b.Normalize()}
+		if got := DistanceFromSegment(x, a, b).Radians(); !float64Near(got, test.want, 1e-15) {
+			t.Errorf("DistanceFromSegment(%v, %v, %v) = %v, want %v", x, a, b, got, test.want)
+		}
+	}
+}
+
+func TestMaxDeviation(t *testing.T) {
+	original := parsePoints("0:0, 1:1, 0:2, -0.5:3, 0:4")
+	tests := []struct {
+		simplified []Point
+		want       s1.Angle
+	}{
+		{original, 0},
+		{parsePoints("0:0, 0:4"), 1 * s1.Degree},
+		{parsePoints("0:0, 1:1, 0:2, 0:4"), 0.5 * s1.Degree},
+		{parsePoints("0:0"), original[4].Distance(original[0])},
+		{nil, 0},
+	}
+	for _, test := range tests {
+		if got := MaxDeviation(original, test.simplified); !float64Near(got.Radians(), test.want.Radians(), 1e-15) {
+			t.Errorf("MaxDeviation(%v, %v) = %v, want %v", original, test.simplified, got, test.want)
+		}
+	}
+}
+
+func TestIntersectionWithError(t *testing.T) {
+	tests := []struct {
+		a0, a1, b0, b1 Point
+		want           Point
+	}{
+		{
+			// Perpendicular edges crossing at a known point.
+			parsePoint("0:-5"), parsePoint("0:5"),
+			parsePoint("-5:0"), parsePoint("5:0"),
+			parsePoint("0:0"),
+		},
+		{
+			// Exactly collinear overlapping edges, which return the
+			// lexicographically smallest endpoint interior to the other edge.
+			parsePoint("0:0"), parsePoint("0:10"),
+			parsePoint("0:5"), parsePoint("0:15"),
+			parsePoint("0:10"),
+		},
+	}
+	for _, test := range tests {
+		for _, p := range [][4]Point{
+			{test.a0, test.a1, test.b0, test.b1},
+			{test.a1, test.a0, test.b0, test.b1},
+			{test.b0, test.b1, test.a0, test.a1},
+			{test.b1, test.b0, test.a1, test.a0},
+		} {
+			got, bound := IntersectionWithError(p[0], p[1], p[2], p[3])
+			if bound > intersectionError {
+				t.Errorf("IntersectionWithError(%v, %v, %v, %v) bound = %v, want <= %v", p[0], p[1], p[2], p[3], bound, intersectionError)
+			}
+			if d := got.Angle(test.want.Vector); d > bound {
+				t.Errorf("IntersectionWithError(%v, %v, %v, %v) = %v, %v; distance to %v is %v", p[0], p[1], p[2], p[3], got, bound, test.want, d)
+			}
+			if x := Intersection(p[0], p[1], p[2], p[3]); x != got {
+				t.Errorf("Intersection(%v, %v, %v, %v) = %v, want %v", p[0], p[1], p[2], p[3], x, got)
+			}
+		}
+	}
+}
+
+func TestIntersectionWithErrorNearlyParallel(t *testing.T) {
+	// Construct pairs of edges that cross at a random point, where the
+	// tangent of the angle between them ranges from 1e-15 to 1e15, and whose
+	// lengths and crossing positions are chosen to stress the computation.
+	for iter := 0; iter < 5000; iter++ {
+		f := randomFrame()
+		p, d1, d2 := f.col(2), f.col(0), f.col(1)
+		slope := 1e-15 * math.Pow(1e30, randomFloat64())
+		d2 = Point{d1.Add(d2.Mul(slope)).Normalize()}
+
+		var a0, a1, b0, b1 Point
+		for {
+			aLen := math.Pow(1e-15, randomFloat64())
+			bLen := math.Pow(1e-15, randomFloat64())
+			aFraction := math.Pow(1e-5, randomFloat64())
+			if oneIn(2) {
+				aFraction = 1 - aFraction
+			}
+			bFraction := math.Pow(1e-5, randomFloat64())
+			if oneIn(2) {
+				bFraction = 1 - bFraction
+			}
+			a0 = Point{p.Sub(d1.Mul(aFraction * aLen)).Normalize()}
+			a1 = Point{p.Add(d1.Mul((1 - aFraction) * aLen)).Normalize()}
+			b0 = Point{p.Sub(d2.Mul(bFraction * bLen)).Normalize()}
+			b1 = Point{p.Add(d2.Mul((1 - bFraction) * bLen)).Normalize()}
+			if NewEdgeCrosser(a0, a1).CrossingSign(b0, b1) == Cross {
+				break
+			}
+		}
+
+		got, bound := IntersectionWithError(a0, a1, b0, b1)
+		if bound > intersectionError {
+			t.Errorf("IntersectionWithError(%v, %v, %v, %v) bound = %v, want <= %v", a0, a1, b0, b1, bound, intersectionError)
+		}
+
+		// The exact intersection is itself rounded to float64, so allow for
+		// its error as well.
+		want, wantBound := intersectionExact(a0, a1, b0, b1)
+		if want.Dot(p.Vector) < 0 {
+			want = Point{want.Mul(-1)}
+		}
+		if d := got.Angle(want.Vector); d > bound+s1.Angle(wantBound) {
+			t.Errorf("IntersectionWithError(%v, %v, %v, %v) = %v, %v; distance to exact intersection %v is %v", a0, a1, b0, b1, got, bound, want, d)
+		}
+	}
+}
+
 func rectBoundForPoints(a, b Point) Rect {
 	bounder := NewRectBounder()
 	bounder.AddPoint(a)
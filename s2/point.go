@@ -176,6 +176,35 @@ func RobustSign(a, b, c Point) Direction {
 	return sign
 }
 
+// SideOfEdgeBatch classifies each of the given points as being to the left
+// (CounterClockwise) or right (Clockwise) of the edge AB, in the same sense as
+// RobustSign(a, b, p). The normal AxB is computed once for the whole batch, so
+// the work per point is a single dot product.
+//
+// This is a fast pre-filter rather than an exact predicate. Each result is
+// exactly what triageSign(a, b, p) returns: a point is reported as
+// Indeterminate whenever the sign of its determinant is not certain to within
+// maxDeterminantError, and every point is Indeterminate when A and B are equal
+// or exactly antipodal. Determinate results always agree with RobustSign. Use
+// RobustSign to resolve the Indeterminate cases.
+func SideOfEdgeBatch(a, b Point, points []Point) []Direction {
+	dirs := make([]Direction, len(points))
+
+	// This is the same floating point computation of (AxB).C as triageSign
+	// performs, so the determinant, and therefore the bound on its error,
+	// is identical for every point.
+	normal := a.Cross(b.Vector)
+	for i, p := range points {
+		switch det := normal.Dot(p.Vector); {
+		case det > maxDeterminantError:
+			dirs[i] = CounterClockwise
+		case det < -maxDeterminantError:
+			dirs[i] = Clockwise
+		}
+	}
+	return dirs
+}
+
 // triageSign returns the direction sign of the points. It returns Indeterminate if two
 // points are identical or the result is uncertain. Uncertain cases can be resolved, if
 // desired, by calling expensiveSign.
@@ -378,6 +407,33 @@ func PointArea(a, b, c Point) float64 {
 		math.Tan(0.5*(s-sb))*math.Tan(0.5*(s-sc)))))
 }
 
+// signedArea returns the area of the triangle ABC with a positive sign if the
+// points are in counterclockwise order and a negative sign otherwise.
+func signedArea(a, b, c Point) float64 {
+	return float64(RobustSign(a, b, c)) * PointArea(a, b, c)
+}
+
+// turnAngle returns the exterior angle at vertex B in the triangle ABC. The
+// return value is positive if ABC is counterclockwise and negative otherwise.
+// If you imagine an ant walking from A to B to C, this is the angle that the
+// ant turns at vertex B (positive = left = CCW, negative = right = CW). This
+// quantity is also known as the "geodesic curvature" at B.
+//
+// The result is undefined if A == B or B == C, but is either -π or π if
+// A == C. All points should be normalized.
+func turnAngle(a, b, c Point) s1.Angle {
+	// We use PointCross to get good accuracy when two points are very close
+	// together, and RobustSign to ensure that the sign is correct for turns
+	// that are close to 180 degrees.
+	angle := a.PointCross(b).Angle(b.PointCross(c).Vector)
+
+	// Don't return RobustSign * angle because it is legal to have A == C.
+	if RobustSign(a, b, c) == CounterClockwise {
+		return angle
+	}
+	return -angle
+}
+
 // TrueCentroid returns the true centroid of the spherical triangle ABC multiplied by the
 // signed area of spherical triangle ABC. The result is not normalized.
 // The reasons for multiplying by the signed area are (1) this is the quantity
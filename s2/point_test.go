@@ -40,8 +40,6 @@ func TestOriginPoint(t *testing.T) {
 	}
 
 	// Check that the origin is not too close to either pole.
-	// The Earth's mean radius in kilometers (according to NASA).
-	const earthRadiusKm = 6371.01
 	if dist := math.Acos(OriginPoint().Z) * earthRadiusKm; dist <= 50 {
 		t.Errorf("Origin point is to close to the North Pole. Got %v, want >= 50km", dist)
 	}
@@ -271,6 +269,54 @@ func TestRobustSign(t *testing.T) {
 	}
 }
 
+func TestSideOfEdgeBatch(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		a, b := randomPoint(), randomPoint()
+		normal := a.Cross(b.Vector)
+		band := 4 * maxDeterminantError / normal.Norm()
+		normal = normal.Normalize()
+
+		// Mix random points with points very close to the edge's great circle.
+		points := []Point{a, b}
+		for j := 0; j < 50; j++ {
+			points = append(points, randomPoint())
+			onEdge := Interpolate(randomFloat64(), a, b)
+			offset := normal.Mul(randomUniformFloat64(-1e-13, 1e-13))
+			points = append(points, Point{onEdge.Add(offset).Normalize()})
+		}
+
+		got := SideOfEdgeBatch(a, b, points)
+		if len(got) != len(points) {
+			t.Fatalf("len(SideOfEdgeBatch(%v, %v, points)) = %d, want %d", a, b, len(got), len(points))
+		}
+		for j, p := range points {
+			if want := triageSign(a, b, p); got[j] != want {
+				t.Errorf("SideOfEdgeBatch(%v, %v, [%v]) = %v, want triageSign result %v", a, b, p, got[j], want)
+			}
+			if j < 2 && got[j] != Indeterminate {
+				t.Errorf("SideOfEdgeBatch(%v, %v, [%v]) = %v, want %v", a, b, p, got[j], Indeterminate)
+			}
+			if got[j] == Indeterminate {
+				// Only points in the band around the great circle may be unresolved.
+				if math.Abs(p.Dot(normal)) > band {
+					t.Errorf("SideOfEdgeBatch(%v, %v, [%v]) = %v, want a determinate result", a, b, p, got[j])
+				}
+				continue
+			}
+			if want := RobustSign(a, b, p); want != Indeterminate && got[j] != want {
+				t.Errorf("SideOfEdgeBatch(%v, %v, [%v]) = %v, want %v", a, b, p, got[j], want)
+			}
+		}
+	}
+
+	// A degenerate edge leaves every point unresolved.
+	for _, dir := range SideOfEdgeBatch(x, x, []Point{y, z}) {
+		if dir != Indeterminate {
+			t.Errorf("SideOfEdgeBatch(%v, %v, ...) = %v, want %v", x, x, dir, Indeterminate)
+		}
+	}
+}
+
 func TestPointDistance(t *testing.T) {
 	tests := []struct {
 		x1, y1, z1 float64
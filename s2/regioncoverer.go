@@ -73,6 +73,11 @@ import (
 // interior coverings - otherwise for regions with small or zero area, the
 // algorithm may spend a lot of time subdividing cells all the way to leaf
 // level to try to find contained cells.
+//
+// A RegionCoverer only holds these options, and all of the working state of a
+// covering is allocated for each call. It is therefore cheap to keep one
+// around and reuse it for any number of coverings, including from multiple
+// goroutines as long as the options are not modified concurrently.
 type RegionCoverer struct {
 	MinLevel int // the minimum cell level to be used.
 	MaxLevel int // the maximum cell level to be used.
@@ -377,6 +382,37 @@ func (rc *RegionCoverer) InteriorCellUnion(region Region) CellUnion {
 	return cu
 }
 
+// RefineCovering returns a covering of the given region that is derived from
+// an existing covering by subdividing only the cells along the boundary of the
+// region. Cells that are contained by the region are kept as they are, while
+// every other cell is replaced by those of its children (LevelMod levels
+// deeper) that intersect the region. Cells that are already at MaxLevel are
+// not subdivided any further.
+//
+// This is useful for progressive approximations, where a coarse covering is
+// computed first and then repeatedly refined. MaxCells is ignored, and the
+// result is not normalized since that could collapse the refined children
+// back into their parents.
+func (rc *RegionCoverer) RefineCovering(region Region, covering CellUnion) CellUnion {
+	c := rc.newCoverer()
+	var refined CellUnion
+	for _, ci := range covering {
+		cell := CellFromCellID(ci)
+		level := ci.Level()
+		if level+c.levelMod > c.maxLevel || region.ContainsCell(cell) {
+			refined = append(refined, ci)
+			continue
+		}
+		end := ci.ChildEndAtLevel(level + c.levelMod)
+		for child := ci.ChildBeginAtLevel(level + c.levelMod); child != end; child = child.Next() {
+			if region.IntersectsCell(CellFromCellID(child)) {
+				refined = append(refined, child)
+			}
+		}
+	}
+	return refined
+}
+
 // FastCovering returns a CellUnion that covers the given region similar to Covering,
 // except that this method is much faster and the coverings are not as tight.
 // All of the usual parameters are respected (MaxCells, MinLevel, MaxLevel, and LevelMod),
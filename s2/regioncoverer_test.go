@@ -149,3 +149,83 @@ func TestRandomCaps(t *testing.T) {
 		checkCovering(t, rc, &r, covering, false)
 	}
 }
+
+func TestRefineCovering(t *testing.T) {
+	rc := &RegionCoverer{MaxLevel: 30, LevelMod: 1, MaxCells: 8}
+	for i := 0; i < 100; i++ {
+		r := Region(randomCap(AvgAreaMetric.Value(12), AvgAreaMetric.Value(4)))
+		covering := rc.Covering(r)
+		refined := rc.RefineCovering(r, covering)
+
+		if len(refined) <= len(covering) {
+			t.Errorf("len(RefineCovering(%v)) = %d, want > %d", covering, len(refined), len(covering))
+		}
+
+		// Every cell in the refined covering must either be an interior cell
+		// kept from the original covering, or a child of a boundary cell.
+		original := make(map[CellID]bool)
+		for _, ci := range covering {
+			original[ci] = true
+		}
+		for _, ci := range refined {
+			if original[ci] {
+				if !r.ContainsCell(CellFromCellID(ci)) {
+					t.Errorf("boundary cell %v was not refined", ci)
+				}
+				continue
+			}
+			parent := ci.immediateParent()
+			if !original[parent] {
+				t.Errorf("refined cell %v is not a child of a cell in the original covering %v", ci, covering)
+			} else if r.ContainsCell(CellFromCellID(parent)) {
+				t.Errorf("interior cell %v was refined into %v", parent, ci)
+			}
+		}
+
+		refined.Normalize()
+		checkCoveringTight(t, &r, refined, true, 0, rc)
+	}
+}
+
+func TestRegionCovererConcurrent(t *testing.T) {
+	// A single coverer can be shared by goroutines that only read its options.
+	rc := &RegionCoverer{MinLevel: 0, MaxLevel: 30, LevelMod: 1, MaxCells: 8}
+	regions := make([]Region, 100)
+	want := make([]CellUnion, len(regions))
+	for i := range regions {
+		regions[i] = randomCap(1e-8, 1)
+		want[i] = rc.Covering(regions[i])
+	}
+
+	got := make([]CellUnion, len(regions))
+	done := make(chan bool)
+	for i := range regions {
+		go func(i int) {
+			got[i] = rc.Covering(regions[i])
+			done <- true
+		}(i)
+	}
+	for range regions {
+		<-done
+	}
+	for i := range regions {
+		if !reflect.DeepEqual(got[i], want[i]) {
+			t.Errorf("concurrent Covering(%v) = %v, want %v", regions[i], got[i], want[i])
+		}
+	}
+}
+
+// BenchmarkRegionCovererCovering covers small caps with a single coverer that
+// is reused across calls, to measure the allocations of each covering.
+func BenchmarkRegionCovererCovering(b *testing.B) {
+	rc := &RegionCoverer{MinLevel: 0, MaxLevel: 30, LevelMod: 1, MaxCells: 4}
+	regions := make([]Region, 100)
+	for i := range regions {
+		regions[i] = randomCap(1e-10, 1e-4)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rc.Covering(regions[i%len(regions)])
+	}
+}
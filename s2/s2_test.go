@@ -43,8 +43,6 @@ func float64Near(x, y, ε float64) bool {
 
 // kmToAngle converts a distance on the Earth's surface to an angle.
 func kmToAngle(km float64) s1.Angle {
-	// The Earth's mean radius in kilometers (according to NASA).
-	const earthRadiusKm = 6371.01
 	return s1.Angle(km / earthRadiusKm)
 }
 
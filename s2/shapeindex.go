@@ -16,6 +16,10 @@ limitations under the License.
 
 package s2
 
+import (
+	"math"
+)
+
 // Shape defines an interface for any s2 type that needs to be indexable.
 type Shape interface {
 	// NumEdges returns the number of edges in this shape.
@@ -114,3 +118,69 @@ func (s *ShapeIndex) At(i int) Shape {
 func (s *ShapeIndex) Reset() {
 	s.shapes = nil
 }
+
+// ShapesContainingPoint returns the ids of all the shapes in the index whose
+// interior contains the given point, in increasing order. Unlike a single
+// containment test, this distinguishes overlapping shapes such as overlapping
+// administrative regions. Shapes without an interior never contain a point.
+//
+// Each shape is tested by starting from whether it contains OriginPoint and
+// counting the edges crossed between OriginPoint and p, using the same
+// semi-open boundary model as Loop.ContainsPoint. Every edge of every shape
+// with an interior is tested, so the cost is linear in the size of the index.
+func (s *ShapeIndex) ShapesContainingPoint(p Point) []int32 {
+	var ids []int32
+	for i, shape := range s.shapes {
+		if shape == nil || !shape.HasInterior() {
+			continue
+		}
+		inside := shape.ContainsOrigin()
+		crosser := NewEdgeCrosser(OriginPoint(), p)
+		for e := 0; e < shape.NumEdges(); e++ {
+			if crosser.EdgeOrVertexCrossing(shape.Edge(e)) {
+				inside = !inside
+			}
+		}
+		if inside {
+			ids = append(ids, int32(i))
+		}
+	}
+	return ids
+}
+
+// ShapeBoundingCaps returns a bounding cap for each shape in the index, in
+// order of shape id. This is useful for partitioning the shapes spatially
+// without constructing a region for each of them.
+//
+// Shapes that provide their own CapBound method use it. For other shapes, the
+// cap is computed from the vertices of their edges, which bounds the edges as
+// well as long as the cap is smaller than a hemisphere. Otherwise, or if such a
+// shape has an interior (which may extend arbitrarily far from its edges), the
+// full cap is used.
+func ShapeBoundingCaps(index *ShapeIndex) []Cap {
+	caps := make([]Cap, index.Len())
+	for i := range caps {
+		shape := index.At(i)
+		if b, ok := shape.(interface {
+			CapBound() Cap
+		}); ok {
+			caps[i] = b.CapBound()
+			continue
+		}
+		if shape.HasInterior() {
+			caps[i] = FullCap()
+			continue
+		}
+
+		var vertices []Point
+		for e := 0; e < shape.NumEdges(); e++ {
+			a, b := shape.Edge(e)
+			vertices = append(vertices, a, b)
+		}
+		caps[i] = MinimalBoundingCap(vertices)
+		if caps[i].Radius() >= math.Pi/2 {
+			caps[i] = FullCap()
+		}
+	}
+	return caps
+}
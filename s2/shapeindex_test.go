@@ -17,7 +17,11 @@ limitations under the License.
 package s2
 
 import (
+	"math"
+	"reflect"
 	"testing"
+
+	"github.com/golang/geo/s1"
 )
 
 // testShape is a minimal implementation of the Shape interface for use in testing
@@ -63,3 +67,89 @@ func TestShapeIndexBasics(t *testing.T) {
 		t.Errorf("index should be empty after reset")
 	}
 }
+
+func TestShapeBoundingCaps(t *testing.T) {
+	loop := LoopFromPoints(parsePoints("0:0, 0:10, 10:10, 10:0"))
+	line := &edgeListShape{edges: [][2]Point{
+		{parsePoint("20:20"), parsePoint("20:25")},
+		{parsePoint("20:25"), parsePoint("25:25")},
+	}}
+	edge := &testShape{parsePoint("-10:-10"), parsePoint("-10:10"), 1}
+	wide := &edgeListShape{edges: [][2]Point{
+		{parsePoint("0:0"), parsePoint("0:120")},
+		{parsePoint("0:120"), parsePoint("0:-120")},
+	}}
+	withInterior := edgeListShapeFromLoops(loop)
+
+	index := NewShapeIndex()
+	for _, s := range []Shape{loop, line, edge, wide, withInterior} {
+		index.Add(s)
+	}
+
+	caps := ShapeBoundingCaps(index)
+	if len(caps) != index.Len() {
+		t.Fatalf("len(ShapeBoundingCaps(index)) = %d, want %d", len(caps), index.Len())
+	}
+	if !caps[0].ApproxEqual(loop.CapBound()) {
+		t.Errorf("ShapeBoundingCaps(index)[0] = %v, want the loop's CapBound %v", caps[0], loop.CapBound())
+	}
+	for i, c := range caps {
+		shape := index.At(i)
+		for e := 0; e < shape.NumEdges(); e++ {
+			a, b := shape.Edge(e)
+			for _, p := range []Point{a, b, Interpolate(0.5, a, b)} {
+				if !c.ContainsPoint(p) {
+					t.Errorf("ShapeBoundingCaps(index)[%d] = %v, does not contain %v", i, c, p)
+				}
+			}
+		}
+	}
+	if caps[1].IsFull() || caps[2].IsFull() {
+		t.Errorf("ShapeBoundingCaps(index) = %v, want non-full caps for small shapes without interior", caps)
+	}
+	if !caps[3].IsFull() {
+		t.Errorf("ShapeBoundingCaps(index)[3] = %v, want full cap for edges spanning more than a hemisphere", caps[3])
+	}
+	if !caps[4].IsFull() {
+		t.Errorf("ShapeBoundingCaps(index)[4] = %v, want full cap for a shape with interior and no CapBound", caps[4])
+	}
+}
+
+func TestShapeIndexShapesContainingPoint(t *testing.T) {
+	index := NewShapeIndex()
+	index.Add(LoopFromPoints(parsePoints("0:0, 0:2, 2:2, 2:0")))
+	index.Add(LoopFromPoints(parsePoints("1:1, 1:3, 3:3, 3:1")))
+	index.Add(&testShape{parsePoint("1.5:1.5"), parsePoint("1.5:1.6"), 1})
+	index.Add(FullLoop())
+
+	tests := []struct {
+		p    Point
+		want []int32
+	}{
+		{parsePoint("1.5:1.5"), []int32{0, 1, 3}},
+		{parsePoint("0.5:0.5"), []int32{0, 3}},
+		{parsePoint("2.5:2.5"), []int32{1, 3}},
+		{parsePoint("10:10"), []int32{3}},
+	}
+	for _, test := range tests {
+		got := index.ShapesContainingPoint(test.p)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("ShapesContainingPoint(%v) = %v, want %v", test.p, got, test.want)
+		}
+	}
+
+	// The result agrees with ContainsPoint for each loop.
+	for i := 0; i < 1000; i++ {
+		p := samplePointFromCap(CapFromCenterAngle(parsePoint("1.5:1.5"), s1.Angle(3*math.Pi/180)))
+		var want []int32
+		for id := 0; id < 2; id++ {
+			if index.At(id).(*Loop).ContainsPoint(p) {
+				want = append(want, int32(id))
+			}
+		}
+		want = append(want, 3)
+		if got := index.ShapesContainingPoint(p); !reflect.DeepEqual(got, want) {
+			t.Errorf("ShapesContainingPoint(%v) = %v, want %v", p, got, want)
+		}
+	}
+}
@@ -0,0 +1,325 @@
+/*
+Copyright 2016 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package s2
+
+import (
+	"math"
+
+	"github.com/golang/geo/s1"
+)
+
+// shapeChains splits the edges of the given shape into chains, where a chain
+// is a maximal run of consecutive edges that each start where the previous
+// edge ended. A chain also ends as soon as it returns to its first vertex, so
+// two loops that share a vertex are still separate chains. The vertices of
+// each chain are returned in order. A closed chain does not repeat its first
+// vertex at the end, so for a Loop this returns exactly its vertices. It also
+// reports whether every chain is closed.
+func shapeChains(shape Shape) (chains [][]Point, closed bool) {
+	closed = true
+	var chain []Point
+	for i := 0; i < shape.NumEdges(); i++ {
+		a, b := shape.Edge(i)
+		if len(chain) > 0 && chain[len(chain)-1] != a {
+			chains = append(chains, chain)
+			chain = nil
+			closed = false
+		}
+		if len(chain) == 0 {
+			chain = []Point{a}
+		}
+		chain = append(chain, b)
+		if b == chain[0] {
+			// The chain is closed, so drop the repeated first vertex.
+			chains = append(chains, chain[:len(chain)-1])
+			chain = nil
+		}
+	}
+	if len(chain) > 0 {
+		chains = append(chains, chain)
+		closed = false
+	}
+	return chains, closed
+}
+
+// SignedArea returns the area enclosed by the chains of edges of the given
+// shape. Each chain contributes either the positive area of the region to its
+// left or the negative area of the region to its right, whichever is smaller
+// in magnitude, so the contribution of a chain is in the range [-2π, 2π]. For
+// example, a small square has a positive area if its vertices are listed
+// counterclockwise and the same area with a negative sign if they are listed
+// clockwise. A chain that bounds exactly a hemisphere contributes 2π.
+//
+// The edges of the shape must be listed as closed chains, where each edge
+// starts where the previous one ended and each chain ends where it started,
+// which is how Loop and Polygon list them. The Shape interface does not
+// guarantee this, so if any chain does not close, SignedArea returns NaN
+// rather than an area that would not mean anything.
+//
+// This works for any shape with an interior, so it can be used to validate the
+// orientation and size of shapes without knowing their concrete type. Shapes
+// without an interior, as well as the empty and full loops (which have no
+// edges), have a signed area of zero.
+func SignedArea(shape Shape) float64 {
+	if !shape.HasInterior() {
+		return 0
+	}
+	chains, closed := shapeChains(shape)
+	if !closed {
+		return math.NaN()
+	}
+	var area float64
+	for _, chain := range chains {
+		a := chainArea(chain)
+		if a > 2*math.Pi {
+			// The region to the right of the chain is smaller.
+			a -= 4 * math.Pi
+		}
+		area += a
+	}
+	return area
+}
+
+// chainArea returns the area of the region to the left of the closed chain
+// with the given vertices, in the range [0, 4π].
+func chainArea(vertices []Point) float64 {
+	// The surface integral is congruent to the area modulo 4π.
+	area := math.Remainder(surfaceIntegral(vertices, signedArea), 4*math.Pi)
+	if area < 0 {
+		area += 4 * math.Pi
+	}
+
+	// The area of a fan triangle whose vertices lie on a common great circle
+	// is ambiguous between 0 and 2π, so the integral can be off by a multiple
+	// of 2π when the chain bounds a region close to a hemisphere, and it may
+	// have the wrong sign when the area is close to zero. By the Gauss-Bonnet
+	// theorem the area is also 2π minus the total turning angle of the chain.
+	// That is less accurate for small regions but never ambiguous, so we use
+	// it to choose between the candidate areas.
+	var curvature float64
+	for i, v := range vertices {
+		prev := vertices[(i+len(vertices)-1)%len(vertices)]
+		next := vertices[(i+1)%len(vertices)]
+		curvature += float64(turnAngle(prev, v, next))
+	}
+	area += 2 * math.Pi * math.Floor((2*math.Pi-curvature-area)/(2*math.Pi)+0.5)
+	return math.Max(0, math.Min(4*math.Pi, area))
+}
+
+// OrientLoopCCW returns the given loop vertices oriented so that their
+// SignedArea is not negative, i.e. so that the loop is counterclockwise around
+// the smaller of the two regions it bounds. If the vertices are already
+// oriented that way they are returned as is, otherwise a reversed copy is
+// returned. Loops with fewer than 3 vertices are returned as is. This is
+// useful for giving shells a consistent orientation when loops are assembled
+// from edges in arbitrary order.
+//
+// Since the signed area of a loop is at most 2π in magnitude, a shell that is
+// larger than a hemisphere cannot be told apart from a small loop listed
+// clockwise, and is reversed into its complement.
+func OrientLoopCCW(vertices []Point) []Point {
+	if len(vertices) < 3 || SignedArea(LoopFromPoints(vertices)) >= 0 {
+		return vertices
+	}
+	reversed := make([]Point, len(vertices))
+	for i, v := range vertices {
+		reversed[len(vertices)-1-i] = v
+	}
+	return reversed
+}
+
+// CountBoundaryCrossings returns the number of times the edges of shape a
+// cross the edges of shape b at a point interior to both edges. Edges that
+// merely share a vertex are not counted. This is cheaper than a full boolean
+// operation when only the number of crossings is needed, such as when
+// checking whether the boundaries of two polygons cross at all.
+//
+// Every pair of edges is tested, so the cost is proportional to the product
+// of the number of edges of the two shapes.
+func CountBoundaryCrossings(a, b Shape) int {
+	var count int
+	for i := 0; i < a.NumEdges(); i++ {
+		a0, a1 := a.Edge(i)
+		crosser := NewEdgeCrosser(a0, a1)
+		for j := 0; j < b.NumEdges(); j++ {
+			if crosser.CrossingSign(b.Edge(j)) == Cross {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// WindingNumber returns the winding number of the edges of the given shape
+// around the point p, i.e. the number of times the boundary winds
+// counterclockwise around p. For a simple loop this is 1 for points inside
+// the loop and 0 for points outside, so it is consistent with ContainsPoint,
+// but for a self-overlapping loop it also distinguishes regions that are
+// wound more than once.
+//
+// Since there is no distinguished "outside" on the sphere, the winding number
+// is measured relative to OriginPoint, which is taken to have a winding
+// number of 1 if the shape contains it and 0 otherwise. The result is then
+// found by counting the signed crossings of the edge from OriginPoint to p.
+// The result is undefined if p lies on an edge of the shape.
+func WindingNumber(shape Shape, p Point) int {
+	origin := OriginPoint()
+	var winding int
+	if shape.ContainsOrigin() {
+		winding = 1
+	}
+
+	crosser := NewEdgeCrosser(origin, p)
+	for i := 0; i < shape.NumEdges(); i++ {
+		a, b := shape.Edge(i)
+		if !crosser.EdgeOrVertexCrossing(a, b) {
+			continue
+		}
+		// Moving from the origin to p crosses the edge from its right side
+		// to its left side if the origin is on the right, which winds the
+		// boundary counterclockwise around p once more.
+		if RobustSign(a, b, origin) == Clockwise {
+			winding++
+		} else {
+			winding--
+		}
+	}
+	return winding
+}
+
+// SampleAlong returns n points spaced equally by arc length along the edges
+// of the given shape, taken in order. Gaps between consecutive edges that do
+// not share a vertex are skipped, so they don't contribute to the spacing.
+//
+// If the edges form an open path, the first and last points are its first and
+// last vertices. If they form a closed path (the last edge ends where the
+// first edge starts), the first point is the first vertex and the points are
+// spaced evenly all the way around, so the start is not repeated at the end.
+// This makes it suitable for placing labels or markers along polylines and
+// polygon boundaries. It returns nil if n is not positive or the shape has no
+// edges.
+func SampleAlong(shape Shape, n int) []Point {
+	numEdges := shape.NumEdges()
+	if n <= 0 || numEdges == 0 {
+		return nil
+	}
+
+	var length s1.Angle
+	for i := 0; i < numEdges; i++ {
+		a, b := shape.Edge(i)
+		length += a.Distance(b)
+	}
+
+	first, _ := shape.Edge(0)
+	_, last := shape.Edge(numEdges - 1)
+	closed := first == last
+	spacing := length / s1.Angle(n)
+	if !closed {
+		if n == 1 {
+			return []Point{first}
+		}
+		spacing = length / s1.Angle(n-1)
+	}
+
+	points := make([]Point, 0, n)
+	var edge int
+	var start s1.Angle // The distance along the shape to the start of edge.
+	for i := 0; i < n; i++ {
+		if !closed && i == n-1 {
+			// Avoid any rounding error in the distance to the end.
+			points = append(points, last)
+			break
+		}
+		dist := s1.Angle(i) * spacing
+		a, b := shape.Edge(edge)
+		for edge+1 < numEdges && start+a.Distance(b) < dist {
+			start += a.Distance(b)
+			edge++
+			a, b = shape.Edge(edge)
+		}
+		points = append(points, InterpolateAtDistance(dist-start, a, b))
+	}
+	return points
+}
+
+// surfaceIntegral computes the oriented surface integral of some quantity f(x)
+// over the loop interior defined by the given vertices, given a function
+// f(A,B,C) that returns the corresponding integral over the spherical triangle
+// ABC. Here "oriented surface integral" means that f(A,B,C) must be the
+// integral of f if ABC is counterclockwise and the integral of -f if ABC is
+// clockwise, and that the result of this function is *either* the integral of
+// f over the loop interior, or the integral of -f over the loop exterior.
+//
+// The triangles are a fan around an origin that is moved whenever the next
+// vertex would be nearly antipodal to it, since the triangle areas are
+// numerically unstable when any of their edges is close to 180 degrees.
+func surfaceIntegral(vertices []Point, f func(a, b, c Point) float64) float64 {
+	// The maximum length of an edge for it to be considered numerically stable.
+	const maxLength = math.Pi - 1e-5
+
+	if len(vertices) < 3 {
+		return 0
+	}
+
+	var sum float64
+	origin := vertices[0]
+	for i := 1; i+1 < len(vertices); i++ {
+		// Let V_i be vertices[i], let O be the current origin, and let
+		// length(A, B) be the length of edge (A, B). At the start of each
+		// iteration the "leading edge" of the triangle fan is (O, V_i), and we
+		// want to extend the triangle fan so that the leading edge is (O, V_i+1).
+		//
+		// Invariants:
+		//  1. length(O, V_i) < maxLength for all (i > 1).
+		//  2. Either O == V_0, or O is approximately perpendicular to V_0.
+		//  3. sum is the oriented integral of f over the area defined by
+		//     (O, V_0, V_1, ..., V_i).
+		if vertices[i+1].Distance(origin) > maxLength {
+			// We are about to create an unstable edge, so choose a new origin O'
+			// for the triangle fan.
+			oldOrigin := origin
+			if origin == vertices[0] {
+				// The following point is well-separated from V_i and V_0 (and
+				// therefore V_i+1 as well).
+				origin = vertices[0].PointCross(vertices[i])
+			} else if vertices[i].Distance(vertices[0]) < maxLength {
+				// All edges of the triangle (O, V_0, V_i) are stable, so we can
+				// revert to using V_0 as the origin.
+				origin = vertices[0]
+			} else {
+				// (O, V_i+1) and (V_0, V_i) are antipodal pairs, and O and V_0 are
+				// perpendicular. Therefore V_0 x O is approximately perpendicular
+				// to all of {O, V_0, V_i, V_i+1}, and we can choose this point O'
+				// as the new origin.
+				origin = Point{vertices[0].Cross(oldOrigin.Vector)}
+
+				// Advance the edge (V_0,O) to (V_0,O').
+				sum += f(vertices[0], oldOrigin, origin)
+			}
+			// Advance the edge (O,V_i) to (O',V_i).
+			sum += f(oldOrigin, vertices[i], origin)
+		}
+		// Advance the edge (O,V_i) to (O,V_i+1).
+		sum += f(origin, vertices[i], vertices[i+1])
+	}
+	// If the origin is not V_0, we need to sum one more triangle.
+	if origin != vertices[0] {
+		// Advance the edge (O,V_n-1) to (O,V_0).
+		sum += f(origin, vertices[len(vertices)-1], vertices[0])
+	}
+	return sum
+}
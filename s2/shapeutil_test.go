@@ -0,0 +1,319 @@
+/*
+Copyright 2016 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package s2
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/geo/s1"
+)
+
+// edgeListShape is a Shape made up of an arbitrary list of edges, for testing
+// shapes with more than one chain.
+type edgeListShape struct {
+	edges       [][2]Point
+	hasInterior bool
+}
+
+// edgeListShapeFromLoops returns an edgeListShape with interior whose edges
+// are those of the given loops, in order.
+func edgeListShapeFromLoops(loops ...*Loop) *edgeListShape {
+	s := &edgeListShape{hasInterior: true}
+	for _, l := range loops {
+		for i := 0; i < l.NumEdges(); i++ {
+			a, b := l.Edge(i)
+			s.edges = append(s.edges, [2]Point{a, b})
+		}
+	}
+	return s
+}
+
+func (s *edgeListShape) NumEdges() int           { return len(s.edges) }
+func (s *edgeListShape) Edge(i int) (a, b Point) { return s.edges[i][0], s.edges[i][1] }
+func (s *edgeListShape) HasInterior() bool       { return s.hasInterior }
+func (s *edgeListShape) ContainsOrigin() bool    { return false }
+
+func TestShapeChains(t *testing.T) {
+	loop := LoopFromPoints(parsePoints("0:0, 0:1, 1:1, 1:0"))
+	chains, closed := shapeChains(loop)
+	if len(chains) != 1 || len(chains[0]) != 4 || !closed {
+		t.Fatalf("shapeChains(%v) = %v, want a single chain of 4 vertices", loop, chains)
+	}
+	for i, v := range chains[0] {
+		if v != loop.Vertex(i) {
+			t.Errorf("shapeChains(%v)[0][%d] = %v, want %v", loop, i, v, loop.Vertex(i))
+		}
+	}
+
+	other := LoopFromPoints(parsePoints("10:10, 10:11, 11:11"))
+	if got, _ := shapeChains(edgeListShapeFromLoops(loop, other)); len(got) != 2 || len(got[0]) != 4 || len(got[1]) != 3 {
+		t.Errorf("shapeChains of two loops = %v, want chains of 4 and 3 vertices", got)
+	}
+
+	// Loops that share their first vertex are still separate chains.
+	shared := LoopFromPoints(parsePoints("0:0, -1:0, -1:-1"))
+	if got, _ := shapeChains(edgeListShapeFromLoops(loop, shared)); len(got) != 2 || len(got[0]) != 4 || len(got[1]) != 3 {
+		t.Errorf("shapeChains of two loops sharing a vertex = %v, want chains of 4 and 3 vertices", got)
+	}
+
+	if got, _ := shapeChains(EmptyLoop()); len(got) != 0 {
+		t.Errorf("shapeChains(EmptyLoop()) = %v, want no chains", got)
+	}
+
+	// Edges that are not listed in order do not form closed chains.
+	shuffled := &edgeListShape{edges: [][2]Point{
+		{loop.Vertex(0), loop.Vertex(1)},
+		{loop.Vertex(2), loop.Vertex(3)},
+		{loop.Vertex(1), loop.Vertex(2)},
+		{loop.Vertex(3), loop.Vertex(0)},
+	}, hasInterior: true}
+	if got, closed := shapeChains(shuffled); closed {
+		t.Errorf("shapeChains(%v) = %v, true, want chains that are not closed", shuffled, got)
+	}
+}
+
+// fanArea returns the signed area of the loop with the given vertices,
+// computed as a fan of triangles around the given center point.
+func fanArea(center Point, vertices []Point) float64 {
+	var area float64
+	for i := range vertices {
+		area += signedArea(center, vertices[i], vertices[(i+1)%len(vertices)])
+	}
+	return area
+}
+
+func TestSignedArea(t *testing.T) {
+	ccw := parsePoints("0:0, 0:1, 1:1, 1:0")
+	cw := parsePoints("1:0, 1:1, 0:1, 0:0")
+	other := parsePoints("20:20, 20:21, 21:21, 21:20")
+	octant := parsePoints("0:0, 0:90, 90:0")
+	// The third vertex is nearly antipodal to the first, which requires
+	// moving the origin of the triangle fan.
+	lens := parsePoints("0:0, -20:90, 0:179.99999, 20:90")
+	// A loop around the north pole that is larger than a hemisphere.
+	northern := parsePoints("-10:0, -10:120, -10:-120")
+	equator := parsePoints("0:0, 0:120, 0:-120")
+
+	squareArea := PointArea(ccw[0], ccw[1], ccw[2]) + PointArea(ccw[0], ccw[2], ccw[3])
+	if got := SignedArea(LoopFromPoints(ccw)); got <= 0 || !float64Near(got, squareArea, 1e-15) {
+		t.Errorf("SignedArea(CCW square) = %v, want %v", got, squareArea)
+	}
+	if got := SignedArea(LoopFromPoints(cw)); got >= 0 || !float64Near(got, -squareArea, 1e-15) {
+		t.Errorf("SignedArea(CW square) = %v, want %v", got, -squareArea)
+	}
+
+	tests := []struct {
+		name  string
+		shape Shape
+		want  float64
+	}{
+		{"empty loop", EmptyLoop(), 0},
+		{"full loop", FullLoop(), 0},
+		{"shape without interior", &testShape{ccw[0], ccw[1], 1}, 0},
+		{"octant", LoopFromPoints(octant), math.Pi / 2},
+		{"reversed octant", LoopFromPoints(parsePoints("90:0, 0:90, 0:0")), -math.Pi / 2},
+		{"lens", LoopFromPoints(lens), fanArea(parsePoint("0:90"), lens)},
+		{"larger than a hemisphere", LoopFromPoints(northern), fanArea(parsePoint("90:0"), northern) - 4*math.Pi},
+		{"northern hemisphere", LoopFromPoints(equator), 2 * math.Pi},
+		{"southern hemisphere", LoopFromPoints(parsePoints("0:-120, 0:120, 0:0")), 2 * math.Pi},
+		{"two chains", edgeListShapeFromLoops(LoopFromPoints(ccw), LoopFromPoints(other)), squareArea + fanArea(other[0], other)},
+		{"shell and reversed hole", edgeListShapeFromLoops(LoopFromPoints(octant), LoopFromPoints(cw)), math.Pi/2 - squareArea},
+		{"chains sharing a vertex", edgeListShapeFromLoops(LoopFromPoints(ccw), LoopFromPoints(parsePoints("0:0, 0:-1, -1:-1, -1:0"))), 2 * squareArea},
+	}
+
+	for _, test := range tests {
+		if got := SignedArea(test.shape); !float64Near(got, test.want, 1e-13) {
+			t.Errorf("%s: SignedArea = %v, want %v", test.name, got, test.want)
+		}
+	}
+
+	// Reversing a loop negates its area, except that both sides of a
+	// hemisphere have an area of 2π.
+	for _, vertices := range [][]Point{ccw, octant, lens, northern, equator} {
+		reversed := make([]Point, len(vertices))
+		for i, v := range vertices {
+			reversed[len(vertices)-1-i] = v
+		}
+		if got := math.Remainder(SignedArea(LoopFromPoints(vertices))+SignedArea(LoopFromPoints(reversed)), 4*math.Pi); !float64Near(got, 0, 1e-13) {
+			t.Errorf("SignedArea(%v) + SignedArea(reversed) = %v, want a multiple of %v", vertices, got, 4*math.Pi)
+		}
+	}
+
+	// The edges of a square listed out of order do not form closed chains.
+	shuffled := &edgeListShape{edges: [][2]Point{
+		{ccw[0], ccw[1]}, {ccw[2], ccw[3]}, {ccw[1], ccw[2]}, {ccw[3], ccw[0]},
+	}, hasInterior: true}
+	if got := SignedArea(shuffled); !math.IsNaN(got) {
+		t.Errorf("SignedArea(%v) = %v, want NaN for edges that do not form closed chains", shuffled, got)
+	}
+}
+
+func TestCountBoundaryCrossings(t *testing.T) {
+	square := LoopFromPoints(parsePoints("0:0, 0:2, 2:2, 2:0"))
+	tests := []struct {
+		name string
+		a, b Shape
+		want int
+	}{
+		{"overlapping squares", square, LoopFromPoints(parsePoints("1:1, 1:3, 3:3, 3:1")), 2},
+		{"offset squares", square, LoopFromPoints(parsePoints("1:-1, 1:3, 3:3, 3:-1")), 2},
+		{"crossed squares", square, LoopFromPoints(parsePoints("-1:0.5, -1:1.5, 3:1.5, 3:0.5")), 4},
+		{"disjoint squares", square, LoopFromPoints(parsePoints("10:10, 10:12, 12:12, 12:10")), 0},
+		{"nested squares", square, LoopFromPoints(parsePoints("0.5:0.5, 0.5:1.5, 1.5:1.5, 1.5:0.5")), 0},
+		{"shared vertex", square, LoopFromPoints(parsePoints("2:2, 2:4, 4:4, 4:2")), 0},
+		{"empty loop", square, EmptyLoop(), 0},
+	}
+	for _, test := range tests {
+		if got := CountBoundaryCrossings(test.a, test.b); got != test.want {
+			t.Errorf("%s: CountBoundaryCrossings = %d, want %d", test.name, got, test.want)
+		}
+		if got := CountBoundaryCrossings(test.b, test.a); got != test.want {
+			t.Errorf("%s: CountBoundaryCrossings (swapped) = %d, want %d", test.name, got, test.want)
+		}
+	}
+}
+
+func TestWindingNumber(t *testing.T) {
+	// pointAt returns a point at the given distance in degrees from 0:0 in
+	// the given direction, measured counterclockwise from east.
+	pointAt := func(dist, theta float64) Point {
+		theta *= math.Pi / 180
+		return PointFromLatLng(LatLngFromDegrees(dist*math.Sin(theta), dist*math.Cos(theta)))
+	}
+
+	// A pentagram drawn counterclockwise, whose central pentagon is wound
+	// twice and whose tips are wound once.
+	var star []Point
+	for i := 0; i < 5; i++ {
+		star = append(star, pointAt(10, 90+float64(i)*144))
+	}
+	pentagram := LoopFromPoints(star)
+
+	tests := []struct {
+		name string
+		p    Point
+		want int
+	}{
+		{"center", pointAt(0, 0), 2},
+		{"central pentagon", pointAt(2, 30), 2},
+		{"top tip", pointAt(8, 90), 1},
+		{"lower left tip", pointAt(8, 234), 1},
+		{"between tips", pointAt(8, 126), 0},
+		{"outside", pointAt(20, 0), 0},
+		{"far away", parsePoint("-60:120"), 0},
+	}
+	for _, test := range tests {
+		if got := WindingNumber(pentagram, test.p); got != test.want {
+			t.Errorf("WindingNumber(pentagram, %s) = %d, want %d", test.name, got, test.want)
+		}
+	}
+
+	// For simple loops of either orientation, the winding number agrees with
+	// ContainsPoint.
+	loops := []*Loop{
+		LoopFromPoints(parsePoints("0:0, 0:10, 10:10, 10:0")),
+		LoopFromPoints(parsePoints("10:0, 10:10, 0:10, 0:0")),
+	}
+	for _, l := range loops {
+		for i := 0; i < 1000; i++ {
+			p := samplePointFromCap(CapFromCenterAngle(parsePoint("5:5"), s1.Angle(20*math.Pi/180)))
+			want := 0
+			if l.ContainsPoint(p) {
+				want = 1
+			}
+			if got := WindingNumber(l, p); got != want {
+				t.Errorf("WindingNumber(%v, %v) = %d, want %d", l, p, got, want)
+			}
+		}
+	}
+}
+
+func TestSampleAlong(t *testing.T) {
+	tests := []struct {
+		shape Shape
+		n     int
+		want  string
+	}{
+		{&testShape{parsePoint("0:0"), parsePoint("0:4"), 1}, 5, "0:0, 0:1, 0:2, 0:3, 0:4"},
+		{&testShape{parsePoint("0:0"), parsePoint("0:4"), 1}, 2, "0:0, 0:4"},
+		{&testShape{parsePoint("0:0"), parsePoint("0:4"), 1}, 1, "0:0"},
+		{LoopFromPoints(parsePoints("0:0, 0:90, 90:0")), 3, "0:0, 0:90, 90:0"},
+		{LoopFromPoints(parsePoints("0:0, 0:90, 90:0")), 6, "0:0, 0:45, 0:90, 45:90, 90:0, 45:0"},
+		{edgeListShapeFromLoops(LoopFromPoints(parsePoints("0:0, 0:90, 90:0"))), 4, "0:0, 0:67.5, 45:90, 67.5:0"},
+		{&edgeListShape{edges: [][2]Point{
+			{parsePoint("0:0"), parsePoint("0:3")},
+			{parsePoint("0:10"), parsePoint("0:11")},
+		}}, 3, "0:0, 0:2, 0:11"},
+	}
+	for _, test := range tests {
+		got := SampleAlong(test.shape, test.n)
+		want := parsePoints(test.want)
+		if len(got) != len(want) {
+			t.Errorf("SampleAlong(%v, %d) = %v, want %v", test.shape, test.n, got, want)
+			continue
+		}
+		for i := range got {
+			if !got[i].ApproxEqual(want[i]) {
+				t.Errorf("SampleAlong(%v, %d)[%d] = %v, want %v", test.shape, test.n, i, got[i], want[i])
+			}
+		}
+	}
+
+	if got := SampleAlong(LoopFromPoints(parsePoints("0:0, 0:1, 1:1")), 0); got != nil {
+		t.Errorf("SampleAlong(loop, 0) = %v, want nil", got)
+	}
+	if got := SampleAlong(EmptyLoop(), 3); got != nil {
+		t.Errorf("SampleAlong(EmptyLoop(), 3) = %v, want nil", got)
+	}
+}
+
+func TestOrientLoopCCW(t *testing.T) {
+	tests := []struct {
+		vertices, want string
+	}{
+		{"0:0, 0:1, 1:1, 1:0", "0:0, 0:1, 1:1, 1:0"},
+		{"1:0, 1:1, 0:1, 0:0", "0:0, 0:1, 1:1, 1:0"},
+		{"0:0, 0:90, 90:0", "0:0, 0:90, 90:0"},
+		{"90:0, 0:90, 0:0", "0:0, 0:90, 90:0"},
+		{"0:0, 0:1", "0:0, 0:1"},
+		// An exact hemisphere is left alone in either orientation.
+		{"0:0, 0:120, 0:-120", "0:0, 0:120, 0:-120"},
+		{"0:-120, 0:120, 0:0", "0:-120, 0:120, 0:0"},
+		// A counterclockwise shell around the north pole that is larger than
+		// a hemisphere has a negative signed area, so it is reversed.
+		{"-10:0, -10:120, -10:-120", "-10:-120, -10:120, -10:0"},
+	}
+	for _, test := range tests {
+		vertices := parsePoints(test.vertices)
+		got := OrientLoopCCW(vertices)
+		want := parsePoints(test.want)
+		if len(got) != len(want) {
+			t.Errorf("OrientLoopCCW(%v) = %v, want %v", vertices, got, want)
+			continue
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Errorf("OrientLoopCCW(%v) = %v, want %v", vertices, got, want)
+				break
+			}
+		}
+		if area := SignedArea(LoopFromPoints(got)); len(got) >= 3 && area < 0 {
+			t.Errorf("SignedArea(OrientLoopCCW(%v)) = %v, want >= 0", vertices, area)
+		}
+	}
+}